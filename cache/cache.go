@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var now = time.Now
+
+// Entry describes the cached page of a url, whose body is stored under the hash of its content
+type Entry struct {
+	URL string `json:"url"`
+	// Fetched is when the page was last fetched or revalidated
+	Fetched      time.Time   `json:"fetched"`
+	Header       http.Header `json:"header,omitempty"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"lastModified,omitempty"`
+	// Body is the sha256 of the body
+	Body string `json:"body"`
+}
+
+// Fresh reports whether the page was fetched or revalidated less than ttl ago
+func (e Entry) Fresh(ttl time.Duration) bool {
+	return now().Sub(e.Fetched) < ttl
+}
+
+// ParseTTL parses the time a cached page is used without being revalidated, a Go
+// duration such as 24h, none when it is empty
+func ParseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache ttl %q, expected a duration such as 24h", ttl)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("the cache ttl cannot be negative, got %s", ttl)
+	}
+	return d, nil
+}
+
+// Cache stores the pages fetched from urls in a directory. The entry of a url is
+// a file named after the hash of the url under entries, and its body a file named
+// after the hash of its content under bodies, so that identical pages are stored once.
+type Cache struct {
+	dir string
+}
+
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// path returns the file of a hash under the sub-directory kind, the files being
+// spread over sub-directories named after the first byte of the hash
+func (c *Cache) path(kind, key string) string {
+	return filepath.Join(c.dir, kind, key[:2], key)
+}
+
+func (c *Cache) entryPath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return c.path("entries", hex.EncodeToString(sum[:]))
+}
+
+// Lookup returns the entry of url, an error matching fs.ErrNotExist when there is none
+func (c *Cache) Lookup(url string) (Entry, error) {
+	data, err := os.ReadFile(c.entryPath(url))
+	if err != nil {
+		return Entry{}, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Entry{}, fmt.Errorf("invalid cache entry of %s: %w", url, err)
+	}
+	return e, nil
+}
+
+// Body returns the body of the entry
+func (c *Cache) Body(e Entry) (io.ReadCloser, error) {
+	if len(e.Body) < 2 {
+		return nil, fmt.Errorf("cache entry of %s has no body: %w", e.URL, fs.ErrNotExist)
+	}
+	return os.Open(c.path("bodies", e.Body))
+}
+
+// Open returns the cached page of url, an error matching fs.ErrNotExist when
+// there is none
+func (c *Cache) Open(url string) (io.ReadCloser, error) {
+	e, err := c.Lookup(url)
+	if err != nil {
+		return nil, err
+	}
+	return c.Body(e)
+}
+
+// Store saves the page of url with its response headers, replacing the cached one.
+// The body and the entry are written to temporary files first, so that a cached
+// page is never partially written.
+func (c *Cache) Store(url string, header http.Header, body io.Reader) (Entry, error) {
+	h := sha256.New()
+	tmp, err := c.writeTemp(filepath.Join(c.dir, "bodies"), io.TeeReader(body, h))
+	if err != nil {
+		return Entry{}, err
+	}
+	defer os.Remove(tmp)
+	e := Entry{URL: url, Body: sum(h)}
+	path := c.path("bodies", e.Body)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return Entry{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return Entry{}, err
+	}
+
+	header = header.Clone()
+	// the cookies of a response are not for later requests
+	header.Del("Set-Cookie")
+	e.Header = header
+	e.ETag, e.LastModified = header.Get("ETag"), header.Get("Last-Modified")
+	err = c.save(&e)
+	return e, err
+}
+
+// Revalidated records that the page of the entry has not changed, updating its
+// validators from the headers of the response
+func (c *Cache) Revalidated(e Entry, header http.Header) (Entry, error) {
+	if etag := header.Get("ETag"); etag != "" {
+		e.ETag = etag
+	}
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		e.LastModified = lastModified
+	}
+	err := c.save(&e)
+	return e, err
+}
+
+// save writes the entry, as fetched now
+func (c *Cache) save(e *Entry) error {
+	e.Fetched = now().UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	path := c.entryPath(e.URL)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := c.writeTemp(filepath.Dir(path), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	return os.Rename(tmp, path)
+}
+
+// writeTemp copies r to a temporary file of dir and returns its name
+func (c *Cache) writeTemp(dir string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func sum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helper reading the cached page of url
+func read(t *testing.T, c *Cache, url string) string {
+	body, err := c.Open(url)
+	assert.Nil(t, err, "Expected the page to be cached")
+	content, _ := io.ReadAll(body)
+	body.Close()
+	return string(content)
+}
+
+// Test a stored page is read back and replaced by the next one
+func TestStoreOpen(t *testing.T) {
+	c := New(t.TempDir())
+	_, err := c.Open("https://example.com/essay")
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "Expected no page before it is stored")
+
+	_, err = c.Store("https://example.com/essay", nil, strings.NewReader("<p>first</p>"))
+	assert.Nil(t, err, "Expected no error storing the page")
+	_, err = c.Store("https://example.com/essay", nil, strings.NewReader("<p>second</p>"))
+	assert.Nil(t, err, "Expected no error replacing the page")
+	assert.Equal(t, "<p>second</p>", read(t, c, "https://example.com/essay"), "Expected the last page stored")
+
+	_, err = c.Open("https://example.com/other")
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "Expected every url to have its own page")
+}
+
+// Test the headers and validators of a page are stored, and identical bodies stored once
+func TestStoreEntry(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	header := http.Header{"Etag": {`"v1"`}, "Last-Modified": {"Sun, 25 Aug 2019 10:00:00 GMT"}, "Set-Cookie": {"session=1"}}
+	stored, err := c.Store("https://example.com/a", header, strings.NewReader("<p>same</p>"))
+	assert.Nil(t, err, "Expected no error storing the page")
+	_, err = c.Store("https://example.com/b", nil, strings.NewReader("<p>same</p>"))
+	assert.Nil(t, err, "Expected no error storing the page")
+
+	e, err := c.Lookup("https://example.com/a")
+	assert.Nil(t, err, "Expected the entry of the page")
+	assert.Equal(t, stored, e, "Expected the entry stored")
+	assert.Equal(t, `"v1"`, e.ETag, "Expected the etag of the page")
+	assert.Equal(t, "Sun, 25 Aug 2019 10:00:00 GMT", e.LastModified, "Expected the last modification of the page")
+	assert.Empty(t, e.Header.Get("Set-Cookie"), "Expected the cookies not to be stored")
+	assert.Equal(t, "<p>same</p>", read(t, c, "https://example.com/b"), "Expected the body of the other page")
+	bodies, _ := filepath.Glob(filepath.Join(dir, "bodies", "*", "*"))
+	assert.Equal(t, 1, len(bodies), "Expected identical bodies to be stored once")
+}
+
+// Test a revalidated page is fresh again with the new validators
+func TestRevalidated(t *testing.T) {
+	c := New(t.TempDir())
+	fetched := time.Date(2019, 8, 25, 10, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fetched }
+	defer func() { now = time.Now }()
+	e, _ := c.Store("https://example.com/a", http.Header{"Etag": {`"v1"`}}, strings.NewReader("<p>page</p>"))
+
+	now = func() time.Time { return fetched.Add(2 * time.Hour) }
+	assert.True(t, e.Fresh(3*time.Hour), "Expected the page to be fresh within the ttl")
+	assert.False(t, e.Fresh(time.Hour), "Expected the page to be stale after the ttl")
+	e, err := c.Revalidated(e, http.Header{"Etag": {`"v2"`}})
+	assert.Nil(t, err, "Expected no error revalidating the page")
+	e, _ = c.Lookup("https://example.com/a")
+	assert.True(t, e.Fresh(time.Hour), "Expected the revalidated page to be fresh")
+	assert.Equal(t, `"v2"`, e.ETag, "Expected the new etag")
+	assert.Equal(t, "<p>page</p>", read(t, c, "https://example.com/a"), "Expected the page to be kept")
+}
+
+// Test a page failing to be read is not cached
+func TestStoreReadError(t *testing.T) {
+	dir := t.TempDir()
+	c := New(dir)
+	_, err := c.Store("https://example.com/essay", nil, io.MultiReader(strings.NewReader("<p>partial"), errReader{}))
+	assert.NotNil(t, err, "Expected the read error")
+	_, err = c.Open("https://example.com/essay")
+	assert.True(t, errors.Is(err, fs.ErrNotExist), "Expected no partial page")
+	entries, _ := os.ReadDir(filepath.Join(dir, "bodies"))
+	assert.Empty(t, entries, "Expected the temporary file to be removed")
+}
+
+// Test the ttl is a positive duration
+func TestParseTTL(t *testing.T) {
+	ttl, err := ParseTTL("36h")
+	assert.Nil(t, err, "Expected no error for a duration")
+	assert.Equal(t, 36*time.Hour, ttl, "Expected the duration")
+	ttl, err = ParseTTL("")
+	assert.Nil(t, err, "Expected no error for an empty ttl")
+	assert.Zero(t, ttl, "Expected pages to be revalidated without a ttl")
+	_, err = ParseTTL("1 day")
+	assert.NotNil(t, err, "Expected an error for an invalid duration")
+	_, err = ParseTTL("-1h")
+	assert.NotNil(t, err, "Expected an error for a negative duration")
+}
+
+type errReader struct{}
+
+func (errReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/cache"
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/export"
+	"github.com/joshy-joy/essay-word-counter/jobs"
+	"github.com/joshy-joy/essay-word-counter/language"
+	"github.com/joshy-joy/essay-word-counter/output"
+	"github.com/joshy-joy/essay-word-counter/render"
+	"github.com/joshy-joy/essay-word-counter/server"
+	"github.com/joshy-joy/essay-word-counter/sources"
+)
+
+// Exit codes of the commands
+const (
+	ExitOK = 0
+	// ExitFailure is returned when a command fails to run
+	ExitFailure = 1
+	// ExitUsage is returned for invalid arguments, flags or configuration
+	ExitUsage = 2
+)
+
+// stdout receives the help and stderr the usage errors of the commands
+var (
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+)
+
+// command is a subcommand of the program
+type command struct {
+	name        string
+	args        string
+	description string
+	// nargs is the number of arguments after the flags
+	nargs int
+	// flags defines the flags of the command on fs, and returns the function
+	// applying their values to the configuration
+	flags func(fs *flag.FlagSet) func()
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{
+		name:        constants.CountCommand,
+		description: "Counts the words of the pages of the urls, the default command",
+		flags:       flags(inputFlags, topFlag, formatFlag, outputFlag, exportFlag, htmlReportFlag, renderFlags, cacheFlags, checkpointFlags, timeoutFlags, progressFlag),
+		run:         func(ctx context.Context, _ []string) error { return jobs.StartWorkerPool(ctx) },
+	},
+	{
+		name:        constants.CrawlCommand,
+		description: "Counts the words of the pages of the seed urls and of the pages of their sites they link to",
+		flags:       flags(inputFlags, crawlFlags, topFlag, formatFlag, outputFlag, exportFlag, htmlReportFlag, renderFlags, cacheFlags, timeoutFlags, progressFlag),
+		run:         func(ctx context.Context, _ []string) error { return jobs.Crawl(ctx) },
+	},
+	{
+		name:        constants.FetchCommand,
+		description: "Downloads the pages of the urls to the cache, which count and extract read them from",
+		flags:       flags(inputFlags, cacheDirFlag),
+		run:         func(ctx context.Context, _ []string) error { return jobs.Fetch(ctx) },
+	},
+	{
+		name:        constants.ExtractCommand,
+		description: "Writes the text extracted from the pages of the urls",
+		flags:       flags(inputFlags, outputFlag, cacheFlags),
+		run:         func(ctx context.Context, _ []string) error { return jobs.Extract(ctx) },
+	},
+	{
+		name:        constants.CompareCommand,
+		args:        "<before> <after>",
+		description: "Compares the words of two corpora, each one a .csv vocabulary export or a file of urls to count",
+		nargs:       2,
+		flags:       flags(topFlag, formatFlag, outputFlag, cacheFlags),
+		run: func(ctx context.Context, args []string) error {
+			return jobs.Compare(ctx, args[0], args[1])
+		},
+	},
+	{
+		name:        constants.ServeCommand,
+		description: "Runs as an HTTP service counting the urls posted to /count",
+		flags:       flags(addrFlag, topFlag, formatFlag, cacheFlags),
+		run: func(ctx context.Context, _ []string) error {
+			// the requests counted at once would share stderr
+			config.SetProgress(false)
+			return server.ListenAndServe(ctx, config.Get().Serve.Addr, server.Handler(jobs.Count))
+		},
+	},
+	{
+		name:        constants.ValidateConfigCommand,
+		args:        "[config.yml]",
+		description: "Checks a configuration file, the production one by default",
+	},
+}
+
+// Run runs the command named by the first argument with the other arguments and
+// returns the exit code. Arguments starting with a flag run the count command.
+func Run(ctx context.Context, args []string) int {
+	if len(args) > 0 && isHelp(args[0]) {
+		usage(stdout)
+		return ExitOK
+	}
+	name := constants.CountCommand
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := find(name)
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", name)
+		usage(stderr)
+		return ExitUsage
+	}
+	if cmd.name == constants.ValidateConfigCommand {
+		return validateConfig(args)
+	}
+
+	// load production configs, the defaults of the flags
+	if err := loadConfig(constants.ProdConfigFilePath); err != nil {
+		fmt.Fprintf(stderr, "error initializing configurations: %v\n", err)
+		return ExitFailure
+	}
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { cmd.usage(fs) }
+	apply := cmd.flags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if fs.NArg() != cmd.nargs {
+		fmt.Fprintf(stderr, "%s expects %d arguments, got %d\n\n", cmd.name, cmd.nargs, fs.NArg())
+		fs.Usage()
+		return ExitUsage
+	}
+	apply()
+	if err := check(cmd.name); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+
+	if err := cmd.run(ctx, fs.Args()); err != nil {
+		log.Printf("error running %s: %v", cmd.name, err)
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// program is the name the program was run with
+func program() string {
+	return filepath.Base(os.Args[0])
+}
+
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+func find(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// usage lists the commands
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags] [arguments]\n\nCommands:\n", program())
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-16s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(w, "\nRun %s <command> --help for the flags of a command. Without a command, the flags are the ones of count.\n", program())
+	fmt.Fprintf(w, "Exit codes: %d on success, %d when the command fails, %d for invalid arguments or configuration.\n", ExitOK, ExitFailure, ExitUsage)
+}
+
+// usage describes the command and its flags
+func (cmd command) usage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintf(w, "Usage: %s\n\n%s.\n", strings.TrimSpace(program()+" "+cmd.name+" [flags] "+cmd.args), cmd.description)
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		fs.PrintDefaults()
+	}
+}
+
+// loadConfig loads the configuration file and the segmentation dictionaries it names
+func loadConfig(path string) error {
+	if err := config.InitConfig(path); err != nil {
+		return err
+	}
+	for lang, path := range config.Get().Language.Dictionaries {
+		if err := language.LoadDictionary(lang, path); err != nil {
+			return fmt.Errorf("error loading %s dictionary: %w", lang, err)
+		}
+	}
+	return nil
+}
+
+// check validates the settings the command uses, once the flags are applied
+func check(name string) error {
+	cfg := config.Get()
+	if !output.IsFormat(cfg.OutputFormat) {
+		return fmt.Errorf("unknown output format %q, expected one of %s", cfg.OutputFormat, strings.Join(output.Formats, ", "))
+	}
+	if !sources.IsFormat(cfg.Input.Format) {
+		return fmt.Errorf("unknown input format %q, expected one of %s", cfg.Input.Format, strings.Join(sources.Formats, ", "))
+	}
+	if err := sources.CheckDateRange(cfg.Input.Since, cfg.Input.Until); err != nil {
+		return err
+	}
+	if _, err := cache.ParseTTL(cfg.Cache.TTL); err != nil {
+		return err
+	}
+	for _, timeout := range [][2]string{{"maximum duration", cfg.Timeouts.MaxDuration}, {"url timeout", cfg.Timeouts.URL}, {"document timeout", cfg.Timeouts.Document}} {
+		if _, err := jobs.ParseTimeout(timeout[0], timeout[1]); err != nil {
+			return err
+		}
+	}
+	if cfg.Progress.Enabled && cfg.Progress.Interval <= 0 {
+		return errors.New("the progress interval must be positive")
+	}
+	if cfg.Cache.Offline && cfg.Cache.Dir == constants.Empty {
+		return errors.New("pages can only be read offline from a cache directory")
+	}
+	if cfg.Cache.Offline && name == constants.FetchCommand {
+		return errors.New("pages cannot be fetched offline")
+	}
+	if name == constants.CompareCommand && cfg.Approximate.Enabled {
+		return errors.New("corpora cannot be compared in approximate mode")
+	}
+	if name == constants.CrawlCommand {
+		if err := checkCrawl(); err != nil {
+			return err
+		}
+	}
+	if name == constants.CountCommand {
+		if err := checkCheckpoint(); err != nil {
+			return err
+		}
+	}
+	if name != constants.CountCommand && name != constants.CrawlCommand {
+		return nil
+	}
+	if path := cfg.ExportPath; path != constants.Empty {
+		if _, err := export.Format(path); err != nil {
+			return err
+		}
+		if cfg.Approximate.Enabled {
+			return errors.New("the complete vocabulary cannot be exported in approximate mode")
+		}
+	}
+	for _, path := range cfg.Render.Paths {
+		if _, _, err := render.Kind(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCheckpoint validates the checkpoint settings of the count command
+func checkCheckpoint() error {
+	cfg := config.Get()
+	checkpoint := cfg.Checkpoint
+	if checkpoint.Path == constants.Empty {
+		if checkpoint.Resume {
+			return errors.New("a count can only be resumed from a checkpoint file")
+		}
+		return nil
+	}
+	if checkpoint.Interval <= 0 {
+		return errors.New("the checkpoint interval must be positive")
+	}
+	if cfg.Approximate.Enabled {
+		return errors.New("counts cannot be checkpointed in approximate mode")
+	}
+	return nil
+}
+
+// checkCrawl validates the patterns and limits of the crawler
+func checkCrawl() error {
+	crawl := config.Get().Crawl
+	for _, patterns := range [][]string{crawl.Include, crawl.Exclude} {
+		if _, err := jobs.CompilePatterns(patterns); err != nil {
+			return err
+		}
+	}
+	if crawl.MaxDepth < 0 || crawl.MaxPages <= 0 || crawl.PerHost <= 0 {
+		return errors.New("the crawl depth cannot be negative, and the numbers of pages and of pages per host must be positive")
+	}
+	return nil
+}
+
+// validateConfig checks the configuration file named by the arguments as every
+// command would use it
+func validateConfig(args []string) int {
+	if len(args) > 0 && isHelp(args[0]) {
+		cmd, _ := find(constants.ValidateConfigCommand)
+		fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		fs.SetOutput(stdout)
+		cmd.usage(fs)
+		return ExitOK
+	}
+	if len(args) > 1 {
+		fmt.Fprintf(stderr, "%s expects at most 1 argument, got %d\n", constants.ValidateConfigCommand, len(args))
+		return ExitUsage
+	}
+	path := constants.ProdConfigFilePath
+	if len(args) == 1 {
+		path = args[0]
+	}
+	err := loadConfig(path)
+	if err == nil {
+		err = check(constants.CrawlCommand)
+	}
+	if err == nil {
+		err = checkCheckpoint()
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "%s is invalid: %v\n", path, err)
+		return ExitFailure
+	}
+	fmt.Fprintf(stdout, "%s is valid\n", path)
+	return ExitOK
+}
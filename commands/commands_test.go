@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const devConfigFilePath = "resources/dev/config.yml"
+
+// run runs the command from the root of the repository, where the configuration
+// files are, and returns its exit code, stdout and stderr
+func run(t *testing.T, args ...string) (int, string, string) {
+	wd, _ := os.Getwd()
+	assert.Nil(t, os.Chdir(".."), "Expected no error changing directory")
+	defer func() { _ = os.Chdir(wd) }()
+	var out, errOut bytes.Buffer
+	stdout, stderr = &out, &errOut
+	defer func() { stdout, stderr = os.Stdout, os.Stderr }()
+	code := Run(context.Background(), args)
+	return code, out.String(), errOut.String()
+}
+
+// Test the help lists every command
+func TestRunHelp(t *testing.T) {
+	code, out, _ := run(t, "--help")
+	assert.Equal(t, ExitOK, code, "Expected help to succeed")
+	for _, cmd := range commands {
+		assert.Contains(t, out, "  "+cmd.name+" ", "Expected %s in the help", cmd.name)
+	}
+
+	code, _, errOut := run(t, "compare", "--help")
+	assert.Equal(t, ExitOK, code, "Expected the help of a command to succeed")
+	assert.Contains(t, errOut, "compare [flags] <before> <after>", "Expected the arguments of the command")
+	assert.Contains(t, errOut, "-top", "Expected the flags of the command")
+}
+
+// Test invalid commands, flags and arguments exit with the usage code
+func TestRunUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"counts"},
+		{"--bogus"},
+		{"count", "--format", "xml"},
+		{"count", "--render", "chart.gif"},
+		{"count", "--since", "yesterday"},
+		{"crawl", "--exclude", "("},
+		{"crawl", "--per-host", "-1"},
+		{"count", "--cache-ttl", "1d"},
+		{"count", "--cache-ttl", "-1h"},
+		{"count", "--resume"},
+		{"count", "--max-duration", "1d"},
+		{"crawl", "--url-timeout", "-1s"},
+		{"count", "--checkpoint", "state.json", "--checkpoint-interval", "-1"},
+		{"compare", "before.csv"},
+		{"fetch", "extra"},
+		{"validate-config", "a.yml", "b.yml"},
+	} {
+		code, _, errOut := run(t, args...)
+		assert.Equal(t, ExitUsage, code, "Expected the usage exit code for %v", args)
+		assert.NotEmpty(t, errOut, "Expected an explanation for %v", args)
+	}
+}
+
+// Test a failing command exits with the failure code
+func TestRunFailure(t *testing.T) {
+	code, _, _ := run(t, "count", "--file", "missing-urls.txt")
+	assert.Equal(t, ExitFailure, code, "Expected the failure exit code for a missing url file")
+
+	// every document failing, the result is written but nothing is counted
+	dir := t.TempDir()
+	for _, name := range []string{"essay-1.docx", "essay-2.pdf"} {
+		assert.Nil(t, os.WriteFile(path.Join(dir, name), []byte("not a document"), 0644), "Expected no error writing the document")
+	}
+	result := path.Join(dir, "result.json")
+	code, _, _ = run(t, "count", "--path", dir, "--format", "json", "--output", result)
+	assert.Equal(t, ExitFailure, code, "Expected the failure exit code when no document is counted")
+	written, err := os.ReadFile(result)
+	assert.Nil(t, err, "Expected the result to be written")
+	assert.Contains(t, string(written), "essay-2.pdf", "Expected the failures in the result")
+}
+
+// Test validate-config checks the configuration file
+func TestValidateConfig(t *testing.T) {
+	code, out, _ := run(t, "validate-config", devConfigFilePath)
+	assert.Equal(t, ExitOK, code, "Expected the dev configuration to be valid")
+	assert.Equal(t, devConfigFilePath+" is valid\n", out, "Expected the configuration to be reported valid")
+
+	file := path.Join(t.TempDir(), "config.yml")
+	content, _ := os.ReadFile(path.Join("..", devConfigFilePath))
+	invalid := bytes.Replace(content, []byte(`outputFormat: "json"`), []byte(`outputFormat: "xml"`), 1)
+	assert.Nil(t, os.WriteFile(file, invalid, 0644), "Expected no error writing the configuration")
+	code, _, errOut := run(t, "validate-config", file)
+	assert.Equal(t, ExitFailure, code, "Expected an invalid configuration to fail")
+	assert.Contains(t, errOut, `unknown output format "xml"`, "Expected the reason")
+}
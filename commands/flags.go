@@ -0,0 +1,166 @@
+package commands
+
+import (
+	"flag"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/output"
+	"github.com/joshy-joy/essay-word-counter/sources"
+)
+
+// flagFunc defines a flag on fs and returns the function applying its value to the configuration
+type flagFunc func(fs *flag.FlagSet) func()
+
+// flags combines the flags of a command
+func flags(defs ...flagFunc) func(fs *flag.FlagSet) func() {
+	return func(fs *flag.FlagSet) func() {
+		applies := make([]func(), len(defs))
+		for i, def := range defs {
+			applies[i] = def(fs)
+		}
+		return func() {
+			for _, apply := range applies {
+				apply()
+			}
+		}
+	}
+}
+
+func inputFlags(fs *flag.FlagSet) func() {
+	var paths []string
+	fs.Func(constants.FileFlagConstantName, "Optional: To set the list of urls, a file, a glob pattern, an http url or - for stdin, can be repeated (default "+strings.Join(sources.Configured().Lists, ", ")+")", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	format := fs.String(constants.InputFormatFlagConstantName, config.Get().Input.Format, "Optional: To set the format of the lists, one of "+strings.Join(sources.Formats, ", ")+", chosen from the extension of every list by default")
+	column := fs.String(constants.ColumnFlagConstantName, config.Get().Input.Column, "Optional: To set the column of csv lists and the field of jsonl lists holding the urls")
+	var sitemaps, feeds []string
+	fs.Func(constants.SitemapFlagConstantName, "Optional: To count the urls of a sitemap or a sitemap index, a file or an http url, can be repeated", func(path string) error {
+		sitemaps = append(sitemaps, path)
+		return nil
+	})
+	fs.Func(constants.FeedFlagConstantName, "Optional: To count the items of an RSS or Atom feed, a file or an http url, can be repeated", func(path string) error {
+		feeds = append(feeds, path)
+		return nil
+	})
+	var documents []string
+	fs.Func(constants.PathFlagConstantName, "Optional: To count a local document or the documents of a directory, text, Markdown, HTML, EPUB, DOCX or PDF, can be repeated", func(path string) error {
+		documents = append(documents, path)
+		return nil
+	})
+	since := fs.String(constants.SinceFlagConstantName, config.Get().Input.Since, "Optional: To only count the sitemap urls and feed items dated from a date or a RFC 3339 time")
+	until := fs.String(constants.UntilFlagConstantName, config.Get().Input.Until, "Optional: To only count the sitemap urls and feed items dated until a date or a RFC 3339 time")
+	return func() {
+		config.SetFilePaths(paths)
+		config.SetInputFormat(*format)
+		config.SetInputColumn(*column)
+		config.SetSitemaps(sitemaps)
+		config.SetFeeds(feeds)
+		config.SetPaths(documents)
+		config.SetDateRange(*since, *until)
+	}
+}
+
+func topFlag(fs *flag.FlagSet) func() {
+	count := fs.Int(constants.TopFlagConstantName, config.Get().ResultLength, "Optional: To set result count")
+	return func() { config.SetTopN(*count) }
+}
+
+func formatFlag(fs *flag.FlagSet) func() {
+	format := fs.String(constants.FormatFlagConstantName, config.Get().OutputFormat, "Optional: To set the output format, one of "+strings.Join(output.Formats, ", "))
+	return func() { config.SetOutputFormat(*format) }
+}
+
+func outputFlag(fs *flag.FlagSet) func() {
+	path := fs.String(constants.OutputFlagConstantName, config.Get().OutputPath, "Optional: To write the result to a file instead of stdout")
+	return func() { config.SetOutputPath(*path) }
+}
+
+func exportFlag(fs *flag.FlagSet) func() {
+	path := fs.String(constants.ExportFlagConstantName, config.Get().ExportPath, "Optional: To export the complete vocabulary to a .db, .sqlite, .parquet or .csv file")
+	return func() { config.SetExportPath(*path) }
+}
+
+func htmlReportFlag(fs *flag.FlagSet) func() {
+	path := fs.String(constants.HTMLReportFlagConstantName, config.Get().HTMLReportPath, "Optional: To write a self-contained HTML report of the run to a file")
+	return func() { config.SetHTMLReportPath(*path) }
+}
+
+func renderFlags(fs *flag.FlagSet) func() {
+	var paths []string
+	fs.Func(constants.RenderFlagConstantName, "Optional: To draw a word cloud or a bar chart of the top words to a .svg or .png file whose name contains \"cloud\" or \"bar\", can be repeated", func(path string) error {
+		paths = append(paths, path)
+		return nil
+	})
+	seed := fs.Int64(constants.SeedFlagConstantName, config.Get().Render.Seed, "Optional: To set the seed of the word cloud layout")
+	return func() {
+		config.SetRenderPaths(paths)
+		config.SetRenderSeed(*seed)
+	}
+}
+
+func cacheDirFlag(fs *flag.FlagSet) func() {
+	dir := fs.String(constants.CacheDirFlagConstantName, config.Get().Cache.Dir, "Optional: To set the directory the pages are cached in")
+	return func() { config.SetCacheDir(*dir) }
+}
+
+func checkpointFlags(fs *flag.FlagSet) func() {
+	path := fs.String(constants.CheckpointFlagConstantName, config.Get().Checkpoint.Path, "Optional: To periodically save the progress of the count to a state file")
+	interval := fs.Int(constants.CheckpointIntervalFlagConstantName, config.Get().Checkpoint.Interval, "Optional: To set the number of seconds between two checkpoints")
+	resume := fs.Bool(constants.ResumeFlagConstantName, config.Get().Checkpoint.Resume, "Optional: To resume the count saved to the state file, skipping the urls done")
+	return func() {
+		config.SetCheckpoint(*path, *interval)
+		config.SetResume(*resume)
+	}
+}
+
+func timeoutFlags(fs *flag.FlagSet) func() {
+	timeouts := config.Get().Timeouts
+	maxDuration := fs.String(constants.MaxDurationFlagConstantName, timeouts.MaxDuration, "Optional: To stop the run after a duration such as 30m, writing the result of the urls done")
+	url := fs.String(constants.URLTimeoutFlagConstantName, timeouts.URL, "Optional: To set the time allowed to fetch a url, retries included, such as 2m")
+	document := fs.String(constants.DocumentTimeoutFlagConstantName, timeouts.Document, "Optional: To set the time allowed to count the words of a document once it is fetched, such as 1m")
+	return func() { config.SetTimeouts(*maxDuration, *url, *document) }
+}
+
+func progressFlag(fs *flag.FlagSet) func() {
+	enabled := fs.Bool(constants.ProgressFlagConstantName, config.Get().Progress.Enabled, "Optional: To show the progress of the run on stderr, --progress=false to hide it")
+	return func() { config.SetProgress(*enabled) }
+}
+
+func cacheFlags(fs *flag.FlagSet) func() {
+	dir := cacheDirFlag(fs)
+	ttl := fs.String(constants.CacheTTLFlagConstantName, config.Get().Cache.TTL, "Optional: To set how long a cached page is used before it is revalidated, such as 24h, 0 to always revalidate")
+	offline := fs.Bool(constants.OfflineFlagConstantName, config.Get().Cache.Offline, "Optional: To only read the cached pages, without fetching any")
+	return func() {
+		dir()
+		config.SetCacheTTL(*ttl)
+		config.SetOffline(*offline)
+	}
+}
+
+func addrFlag(fs *flag.FlagSet) func() {
+	addr := fs.String(constants.AddrFlagConstantName, config.Get().Serve.Addr, "Optional: To set the address the service listens on")
+	return func() { config.SetServeAddr(*addr) }
+}
+
+func crawlFlags(fs *flag.FlagSet) func() {
+	var include, exclude []string
+	fs.Func(constants.IncludeFlagConstantName, "Optional: To only follow the links matching a regular expression, can be repeated", func(pattern string) error {
+		include = append(include, pattern)
+		return nil
+	})
+	fs.Func(constants.ExcludeFlagConstantName, "Optional: To not follow the links matching a regular expression, can be repeated", func(pattern string) error {
+		exclude = append(exclude, pattern)
+		return nil
+	})
+	crawl := config.Get().Crawl
+	maxDepth := fs.Int(constants.MaxDepthFlagConstantName, crawl.MaxDepth, "Optional: To set the number of links followed from a seed url")
+	maxPages := fs.Int(constants.MaxPagesFlagConstantName, crawl.MaxPages, "Optional: To set the number of pages crawled")
+	perHost := fs.Int(constants.PerHostFlagConstantName, crawl.PerHost, "Optional: To set the number of pages fetched at once from a host")
+	return func() {
+		config.SetCrawlPatterns(include, exclude)
+		config.SetCrawlLimits(*maxDepth, *maxPages, *perHost)
+	}
+}
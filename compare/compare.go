@@ -0,0 +1,129 @@
+package compare
+
+import (
+	"math"
+	"sort"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// zeroCount replaces the count of a word missing from a corpus in the log ratio,
+// which is otherwise infinite
+const zeroCount = 0.5
+
+// Compare compares the complete word frequencies of two corpora, ranked from the
+// most frequent word. Every list of the comparison keeps its n first words, all
+// of them when n is 0.
+func Compare(before, after []models.WordCount, n int) models.Comparison {
+	var c models.Comparison
+	terms := make(map[string]*models.TermChange)
+	term := func(word string) *models.TermChange {
+		t, ok := terms[word]
+		if !ok {
+			t = &models.TermChange{Word: word}
+			terms[word] = t
+		}
+		return t
+	}
+	for _, w := range before {
+		t := term(w.Word)
+		t.RankBefore, t.CountBefore = w.Rank, w.Count
+		c.WordsBefore += w.Count
+	}
+	for _, w := range after {
+		t := term(w.Word)
+		t.RankAfter, t.CountAfter = w.Rank, w.Count
+		c.WordsAfter += w.Count
+	}
+
+	for _, t := range terms {
+		t.LogRatio = round(logRatio(t.CountBefore, t.CountAfter, c.WordsBefore, c.WordsAfter))
+		t.ChiSquared = round(chiSquared(t.CountBefore, t.CountAfter, c.WordsBefore, c.WordsAfter))
+		switch {
+		case t.CountBefore == 0:
+			c.New = append(c.New, *t)
+		case t.CountAfter == 0:
+			c.Disappeared = append(c.Disappeared, *t)
+		case t.RankAfter < t.RankBefore:
+			c.Rising = append(c.Rising, *t)
+		case t.RankAfter > t.RankBefore:
+			c.Falling = append(c.Falling, *t)
+		}
+		c.Keywords = append(c.Keywords, *t)
+	}
+
+	// the largest changes come first, ties being broken by rank, so that the comparison is the same on every run
+	c.Rising = top(c.Rising, n, func(a, b models.TermChange) bool {
+		return descending(a.RankBefore-a.RankAfter, b.RankBefore-b.RankAfter, a.RankAfter, b.RankAfter)
+	})
+	c.Falling = top(c.Falling, n, func(a, b models.TermChange) bool {
+		return descending(a.RankAfter-a.RankBefore, b.RankAfter-b.RankBefore, a.RankBefore, b.RankBefore)
+	})
+	c.New = top(c.New, n, func(a, b models.TermChange) bool {
+		return descending(a.CountAfter, b.CountAfter, a.RankAfter, b.RankAfter)
+	})
+	c.Disappeared = top(c.Disappeared, n, func(a, b models.TermChange) bool {
+		return descending(a.CountBefore, b.CountBefore, a.RankBefore, b.RankBefore)
+	})
+	c.Keywords = top(c.Keywords, n, func(a, b models.TermChange) bool {
+		if a.ChiSquared != b.ChiSquared {
+			return a.ChiSquared > b.ChiSquared
+		}
+		return a.Word < b.Word
+	})
+	return c
+}
+
+// descending orders by a key in descending order, then by a tie-breaker in ascending order
+func descending(key, otherKey, tie, otherTie int) bool {
+	if key != otherKey {
+		return key > otherKey
+	}
+	return tie < otherTie
+}
+
+// top sorts the terms and keeps the n first ones, never returning nil
+func top(terms []models.TermChange, n int, less func(a, b models.TermChange) bool) []models.TermChange {
+	sort.Slice(terms, func(i, j int) bool { return less(terms[i], terms[j]) })
+	if n > 0 && len(terms) > n {
+		terms = terms[:n]
+	}
+	if terms == nil {
+		return []models.TermChange{}
+	}
+	return terms
+}
+
+// logRatio returns the binary logarithm of the relative frequency of a word after
+// over its relative frequency before
+func logRatio(before, after, totalBefore, totalAfter int) float64 {
+	if totalBefore == 0 || totalAfter == 0 {
+		return 0
+	}
+	b, a := float64(before), float64(after)
+	if b == 0 {
+		b = zeroCount
+	}
+	if a == 0 {
+		a = zeroCount
+	}
+	return math.Log2((a / float64(totalAfter)) / (b / float64(totalBefore)))
+}
+
+// chiSquared returns Pearson's chi-squared statistic of the contingency table of
+// the word counts and the counts of the other words in both corpora
+func chiSquared(before, after, totalBefore, totalAfter int) float64 {
+	a, b := float64(before), float64(after)
+	c, d := float64(totalBefore)-a, float64(totalAfter)-b
+	n := a + b + c + d
+	denominator := (a + b) * (c + d) * (a + c) * (b + d)
+	if denominator == 0 {
+		return 0
+	}
+	return n * (a*d - b*c) * (a*d - b*c) / denominator
+}
+
+// round keeps 4 decimals so that the scores print the same on every platform
+func round(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
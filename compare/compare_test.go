@@ -0,0 +1,56 @@
+package compare
+
+import (
+	"math"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	before = []models.WordCount{{Rank: 1, Word: "the", Count: 50}, {Rank: 2, Word: "cart", Count: 20}, {Rank: 3, Word: "sony", Count: 10}, {Rank: 4, Word: "yamaha", Count: 5}}
+	after  = []models.WordCount{{Rank: 1, Word: "the", Count: 50}, {Rank: 2, Word: "sony", Count: 30}, {Rank: 3, Word: "twitter", Count: 15}, {Rank: 4, Word: "cart", Count: 5}}
+)
+
+// words returns the words of the terms
+func words(terms []models.TermChange) []string {
+	result := make([]string, len(terms))
+	for i, t := range terms {
+		result[i] = t.Word
+	}
+	return result
+}
+
+// Test Compare sorts the words by kind of change
+func TestCompare(t *testing.T) {
+	c := Compare(before, after, 0)
+	assert.Equal(t, 85, c.WordsBefore, "Expected the words counted before")
+	assert.Equal(t, 100, c.WordsAfter, "Expected the words counted after")
+	assert.Equal(t, []string{"sony"}, words(c.Rising), "Rising words mismatch")
+	assert.Equal(t, []string{"cart"}, words(c.Falling), "Falling words mismatch")
+	assert.Equal(t, []string{"twitter"}, words(c.New), "New words mismatch")
+	assert.Equal(t, []string{"yamaha"}, words(c.Disappeared), "Disappeared words mismatch")
+	assert.Equal(t, models.TermChange{Word: "sony", RankBefore: 3, RankAfter: 2, CountBefore: 10, CountAfter: 30, LogRatio: 1.3505, ChiSquared: 9.0155}, c.Rising[0], "Expected the ranks, counts and scores of the word")
+	assert.Equal(t, 5, len(c.Keywords), "Expected every word as keyword")
+	assert.Equal(t, "twitter", c.Keywords[0].Word, "Expected the new word to be the strongest keyword")
+	assert.Equal(t, "the", c.Keywords[4].Word, "Expected the most stable word to be the weakest keyword")
+}
+
+// Test Compare keeps the n first words of every list and never returns nil lists
+func TestCompareTop(t *testing.T) {
+	c := Compare(before, after, 2)
+	assert.Equal(t, []string{"twitter", "cart"}, words(c.Keywords), "Expected the 2 strongest keywords")
+	c = Compare(before, before, 2)
+	assert.NotNil(t, c.Rising, "Expected an empty list rather than nil")
+	assert.Empty(t, c.New, "Expected no new word in identical corpora")
+	assert.Equal(t, 0.0, c.Keywords[0].ChiSquared, "Expected no keyness in identical corpora")
+}
+
+// Test the scores of words missing from a corpus are finite
+func TestScores(t *testing.T) {
+	assert.InDelta(t, math.Log2(10.0/100/(0.5/100)), logRatio(0, 10, 100, 100), 1e-9, "Expected a count of 0.5 for a missing word")
+	assert.Equal(t, 0.0, logRatio(1, 1, 0, 10), "Expected no ratio against an empty corpus")
+	assert.InDelta(t, 200.0*1000*1000/(10*190*100*100), chiSquared(0, 10, 100, 100), 1e-9, "Chi-squared mismatch")
+	assert.Equal(t, 0.0, chiSquared(0, 0, 0, 0), "Expected no score without words")
+}
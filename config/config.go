@@ -1,7 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/tokens"
+	"github.com/joshy-joy/essay-word-counter/utils/sketch"
 	"gopkg.in/yaml.v3"
 	"os"
 )
@@ -16,9 +19,102 @@ type Cgf struct {
 	External struct {
 		Timeout int64 `yaml:"timeoutInSeconds"`
 	} `yaml:"external"`
-	DefaultFilePath string `yaml:"defaultFilePath"`
-	ResultLength    int    `yaml:"resultLength"`
-	WordMinLength   int    `yaml:"wordMinLength"`
+	Language struct {
+		Detect          bool   `yaml:"detect"`
+		Default         string `yaml:"default"`
+		RemoveStopWords bool   `yaml:"removeStopWords"`
+		Stem            bool   `yaml:"stem"`
+		// Dictionaries maps a language to a dictionary file replacing the embedded one
+		Dictionaries map[string]string `yaml:"dictionaries"`
+	} `yaml:"language"`
+	// Approximate counts the words in a fixed amount of memory instead of an exact map
+	Approximate struct {
+		Enabled   bool    `yaml:"enabled"`
+		Algorithm string  `yaml:"algorithm"`
+		Epsilon   float64 `yaml:"epsilon"`
+		Delta     float64 `yaml:"delta"`
+	} `yaml:"approximate"`
+	Tokens          tokens.Rules `yaml:"tokens"`
+	DefaultFilePath string       `yaml:"defaultFilePath"`
+	// Input tells where and how the url lists are read
+	Input struct {
+		// Files are the url lists, the default file path when empty
+		Files []string `yaml:"files"`
+		// Format is text, csv or jsonl, chosen from the extension of every list when empty
+		Format string `yaml:"format"`
+		// Column is the column of csv lists and the field of jsonl lists holding the urls
+		Column string `yaml:"column"`
+		// Sitemaps are sitemaps or sitemap indexes whose urls are counted
+		Sitemaps []string `yaml:"sitemaps"`
+		// Feeds are RSS or Atom feeds whose items are counted
+		Feeds []string `yaml:"feeds"`
+		// Paths are local documents, or directories of documents, which are counted
+		Paths []string `yaml:"paths"`
+		// Since and Until bound the lastmod of the sitemap entries and the date
+		// of the feed items, a date or a RFC 3339 time, unbounded when empty
+		Since string `yaml:"since"`
+		Until string `yaml:"until"`
+	} `yaml:"input"`
+	ResultLength  int    `yaml:"resultLength"`
+	WordMinLength int    `yaml:"wordMinLength"`
+	OutputFormat  string `yaml:"outputFormat"`
+	// OutputPath is the file the result is written to, stdout when empty
+	OutputPath string `yaml:"outputPath"`
+	// ExportPath is the file the complete vocabulary is exported to, none when empty
+	ExportPath string `yaml:"exportPath"`
+	// HTMLReportPath is the file a self-contained HTML report of the run is written to, none when empty
+	HTMLReportPath string `yaml:"htmlReport"`
+	// Render lists the charts drawn from the top words and the seed of their layout
+	Render struct {
+		Paths []string `yaml:"paths"`
+		Seed  int64    `yaml:"seed"`
+	} `yaml:"render"`
+	// Cache is the directory the pages fetched are saved to and read from, none
+	// when empty
+	Cache struct {
+		Dir string `yaml:"dir"`
+		// TTL is how long a cached page is used before it is revalidated, a Go duration
+		TTL string `yaml:"ttl"`
+		// Offline only reads the cached pages, the others failing
+		Offline bool `yaml:"offline"`
+	} `yaml:"cache"`
+	// Checkpoint is the state file the progress of the count command is saved to
+	// every Interval seconds, none when empty, and resumed from with Resume
+	Checkpoint struct {
+		Path     string `yaml:"path"`
+		Interval int    `yaml:"intervalInSeconds"`
+		Resume   bool   `yaml:"resume"`
+	} `yaml:"checkpoint"`
+	// Timeouts bound the whole run, the fetching of every url, retries included,
+	// and the processing of every document, Go durations, unbounded when empty
+	Timeouts struct {
+		MaxDuration string `yaml:"maxDuration"`
+		URL         string `yaml:"url"`
+		Document    string `yaml:"document"`
+	} `yaml:"timeouts"`
+	// Progress shows the progress of a run on stderr, redrawn on a terminal and
+	// logged every Interval seconds otherwise
+	Progress struct {
+		Enabled  bool `yaml:"enabled"`
+		Interval int  `yaml:"intervalInSeconds"`
+	} `yaml:"progress"`
+	// Crawl bounds the pages discovered from the seed urls by the crawl command
+	Crawl struct {
+		// Include and Exclude are regular expressions the links followed must
+		// match, any of Include when there is one and none of Exclude
+		Include []string `yaml:"include"`
+		Exclude []string `yaml:"exclude"`
+		// MaxDepth is the number of links followed from a seed, MaxPages the
+		// number of pages counted and PerHost the number of pages fetched at once
+		// from a host
+		MaxDepth int `yaml:"maxDepth"`
+		MaxPages int `yaml:"maxPages"`
+		PerHost  int `yaml:"perHost"`
+	} `yaml:"crawl"`
+	// Serve is the address the service listens on
+	Serve struct {
+		Addr string `yaml:"addr"`
+	} `yaml:"serve"`
 }
 
 var config *Cgf
@@ -36,17 +132,85 @@ func InitConfig(path string) error {
 	if err != nil {
 		return err
 	}
+	if err = validate(cfg); err != nil {
+		return err
+	}
 	config = &cfg
 	return nil
 }
 
+// validate checks the settings which cannot be used as they are
+func validate(cfg Cgf) error {
+	if err := cfg.Tokens.Validate(); err != nil {
+		return err
+	}
+	approximate := cfg.Approximate
+	if !approximate.Enabled {
+		return nil
+	}
+	if approximate.Algorithm != sketch.SpaceSavingAlgorithm && approximate.Algorithm != sketch.CountMinSketchAlgorithm {
+		return fmt.Errorf("unknown approximate algorithm %q", approximate.Algorithm)
+	}
+	if approximate.Epsilon <= 0 || approximate.Epsilon >= 1 {
+		return fmt.Errorf("approximate epsilon must be between 0 and 1, got %v", approximate.Epsilon)
+	}
+	if approximate.Algorithm == sketch.CountMinSketchAlgorithm && (approximate.Delta <= 0 || approximate.Delta >= 1) {
+		return fmt.Errorf("approximate delta must be between 0 and 1, got %v", approximate.Delta)
+	}
+	return nil
+}
+
 func Get() Cgf {
 	return *config
 }
 
-func SetFilePath(path string) {
-	if path != constants.Empty {
-		config.DefaultFilePath = path
+// Set replaces the whole configuration
+func Set(cfg Cgf) {
+	config = &cfg
+}
+
+func SetFilePaths(paths []string) {
+	if len(paths) > 0 {
+		config.Input.Files = paths
+	}
+}
+
+func SetInputFormat(format string) {
+	if format != constants.Empty {
+		config.Input.Format = format
+	}
+}
+
+func SetInputColumn(column string) {
+	if column != constants.Empty {
+		config.Input.Column = column
+	}
+}
+
+func SetSitemaps(paths []string) {
+	if len(paths) > 0 {
+		config.Input.Sitemaps = paths
+	}
+}
+
+func SetFeeds(paths []string) {
+	if len(paths) > 0 {
+		config.Input.Feeds = paths
+	}
+}
+
+func SetPaths(paths []string) {
+	if len(paths) > 0 {
+		config.Input.Paths = paths
+	}
+}
+
+func SetDateRange(since, until string) {
+	if since != constants.Empty {
+		config.Input.Since = since
+	}
+	if until != constants.Empty {
+		config.Input.Until = until
 	}
 }
 
@@ -55,3 +219,108 @@ func SetTopN(count int) {
 		config.ResultLength = count
 	}
 }
+
+func SetOutputFormat(format string) {
+	if format != constants.Empty {
+		config.OutputFormat = format
+	}
+}
+
+func SetOutputPath(path string) {
+	if path != constants.Empty {
+		config.OutputPath = path
+	}
+}
+
+func SetExportPath(path string) {
+	if path != constants.Empty {
+		config.ExportPath = path
+	}
+}
+
+func SetHTMLReportPath(path string) {
+	if path != constants.Empty {
+		config.HTMLReportPath = path
+	}
+}
+
+func SetRenderPaths(paths []string) {
+	if len(paths) > 0 {
+		config.Render.Paths = paths
+	}
+}
+
+func SetRenderSeed(seed int64) {
+	config.Render.Seed = seed
+}
+
+func SetCacheDir(dir string) {
+	if dir != constants.Empty {
+		config.Cache.Dir = dir
+	}
+}
+
+func SetCacheTTL(ttl string) {
+	if ttl != constants.Empty {
+		config.Cache.TTL = ttl
+	}
+}
+
+func SetOffline(offline bool) {
+	config.Cache.Offline = offline
+}
+
+func SetCheckpoint(path string, interval int) {
+	if path != constants.Empty {
+		config.Checkpoint.Path = path
+	}
+	if interval != 0 {
+		config.Checkpoint.Interval = interval
+	}
+}
+
+func SetResume(resume bool) {
+	config.Checkpoint.Resume = resume
+}
+
+func SetTimeouts(maxDuration, url, document string) {
+	if maxDuration != constants.Empty {
+		config.Timeouts.MaxDuration = maxDuration
+	}
+	if url != constants.Empty {
+		config.Timeouts.URL = url
+	}
+	if document != constants.Empty {
+		config.Timeouts.Document = document
+	}
+}
+
+func SetProgress(enabled bool) {
+	config.Progress.Enabled = enabled
+}
+
+func SetCrawlPatterns(include, exclude []string) {
+	if len(include) > 0 {
+		config.Crawl.Include = include
+	}
+	if len(exclude) > 0 {
+		config.Crawl.Exclude = exclude
+	}
+}
+
+// SetCrawlLimits sets the limits of the crawler, a depth of 0 crawling the seeds only
+func SetCrawlLimits(maxDepth, maxPages, perHost int) {
+	config.Crawl.MaxDepth = maxDepth
+	if maxPages != 0 {
+		config.Crawl.MaxPages = maxPages
+	}
+	if perHost != 0 {
+		config.Crawl.PerHost = perHost
+	}
+}
+
+func SetServeAddr(addr string) {
+	if addr != constants.Empty {
+		config.Serve.Addr = addr
+	}
+}
@@ -27,6 +27,13 @@ func TestInitConfigSuccess(t *testing.T) {
 	assert.Nil(t, err, "Expected no error from InitConfig with valid file")
 }
 
+// Test the production configuration caches no page unless a cache directory is given
+func TestInitConfigProdNoCache(t *testing.T) {
+	defer func() { _ = InitConfig(devConfigFilePath) }()
+	assert.Nil(t, InitConfig("../resources/prod/config.yml"), "Expected no error from InitConfig with the production file")
+	assert.Empty(t, Get().Cache.Dir, "No page should be cached by default")
+}
+
 // Test InitConfig with a missing configuration file
 func TestInitConfigErrorMissingFile(t *testing.T) {
 	err := InitConfig("./non_existent_file.yml")
@@ -48,6 +55,22 @@ func TestInitConfigErrorInvalidYAML(t *testing.T) {
 	defer removeTestConfig()
 }
 
+// Test InitConfig rejects invalid approximate counting and token settings
+func TestInitConfigErrorInvalidSettings(t *testing.T) {
+	defer removeTestConfig()
+	invalid := []string{
+		"approximate:\n  enabled: true\n  algorithm: \"lossyCounting\"\n  epsilon: 0.01\n",
+		"tokens:\n  numbers: \"round\"\n",
+		"tokens:\n  hyphens: \"expand\"\n",
+		"approximate:\n  enabled: true\n  algorithm: \"spaceSaving\"\n  epsilon: 0\n",
+		"approximate:\n  enabled: true\n  algorithm: \"countMinSketch\"\n  epsilon: 0.01\n  delta: 1\n",
+	}
+	for _, content := range invalid {
+		assert.Nil(t, createTestConfig(content), "Expected no error while creating the config file")
+		assert.NotNil(t, InitConfig(testConfigPath), "Expected an error for %q", content)
+	}
+}
+
 // Test Get function to ensure it returns the correct configuration
 func TestGetConfigSuccess(t *testing.T) {
 	err := InitConfig(devConfigFilePath)
@@ -56,18 +79,63 @@ func TestGetConfigSuccess(t *testing.T) {
 	assert.Equal(t, 2, cfg.WebScrapper.Count, "WebScrapper count should be 2")
 	assert.Equal(t, 2, cfg.Tokenizer.Count, "Tokenizer count should be 2")
 	assert.Equal(t, int64(30), cfg.External.Timeout, "External timeout should be 30")
+	assert.True(t, cfg.Language.Detect, "Language detection should be enabled")
+	assert.Equal(t, "en", cfg.Language.Default, "Default language should be en")
+	assert.False(t, cfg.Language.RemoveStopWords, "Stop words removal should be disabled")
+	assert.False(t, cfg.Language.Stem, "Stemming should be disabled")
+	assert.False(t, cfg.Approximate.Enabled, "Approximate counting should be disabled")
+	assert.Equal(t, "spaceSaving", cfg.Approximate.Algorithm, "Approximate algorithm should be spaceSaving")
+	assert.Equal(t, 0.0001, cfg.Approximate.Epsilon, "Approximate epsilon mismatch")
+	assert.Equal(t, "keep", cfg.Tokens.Contractions, "Contractions should be kept")
+	assert.Equal(t, "drop", cfg.Tokens.URLs, "URLs should be dropped")
 	assert.Equal(t, "./example/test.txt", cfg.DefaultFilePath, "Default file path mismatch")
 	assert.Equal(t, 2, cfg.ResultLength, "Result length should be 15")
 	assert.Equal(t, 3, cfg.WordMinLength, "Word minimum length should be 5")
+	assert.Equal(t, "json", cfg.OutputFormat, "Output format should be json")
+	assert.Empty(t, cfg.OutputPath, "Output path should be empty")
+	assert.Empty(t, cfg.ExportPath, "Export path should be empty")
+	assert.Empty(t, cfg.HTMLReportPath, "No HTML report should be written")
+	assert.Empty(t, cfg.Render.Paths, "No chart should be rendered")
+	assert.Equal(t, int64(1), cfg.Render.Seed, "Render seed should be 1")
+	assert.Empty(t, cfg.Input.Files, "Input files should default to the default file path")
+	assert.Empty(t, cfg.Input.Format, "Input format should be chosen from the extension")
+	assert.Equal(t, "url", cfg.Input.Column, "Input column should be url")
+	assert.Empty(t, cfg.Input.Paths, "No local documents should be counted by default")
+	assert.Empty(t, cfg.Cache.Dir, "No page should be cached")
+	assert.Equal(t, "24h", cfg.Cache.TTL, "Cached pages should be revalidated after a day")
+	assert.False(t, cfg.Cache.Offline, "Pages should be fetched by default")
+	assert.Empty(t, cfg.Checkpoint.Path, "No checkpoint should be saved by default")
+	assert.Equal(t, 30, cfg.Checkpoint.Interval, "Checkpoints should be saved every 30 seconds")
+	assert.Empty(t, cfg.Timeouts.MaxDuration, "The run should not be bounded by default")
+	assert.Equal(t, "2m", cfg.Timeouts.URL, "Urls should be fetched within 2 minutes")
+	assert.Equal(t, "1m", cfg.Timeouts.Document, "Documents should be processed within a minute")
+	assert.False(t, cfg.Progress.Enabled, "No progress should be shown")
+	assert.Equal(t, 10, cfg.Progress.Interval, "Progress should be logged every 10 seconds")
+	assert.Equal(t, 2, cfg.Crawl.MaxDepth, "Crawl depth should be 2")
+	assert.Equal(t, 10, cfg.Crawl.MaxPages, "Crawled pages should be 10")
+	assert.Equal(t, 2, cfg.Crawl.PerHost, "Pages fetched at once from a host should be 2")
+	assert.Equal(t, ":8080", cfg.Serve.Addr, "Service address should be :8080")
 }
 
-// Test SetFilePath to ensure it updates the DefaultFilePath correctly
-func TestSetFilePathSuccess(t *testing.T) {
+// Test SetCrawlLimits to ensure a depth of 0 is kept and the other limits are only set when given
+func TestSetCrawlLimitsSuccess(t *testing.T) {
 	err := InitConfig(devConfigFilePath)
 	assert.Nil(t, err, "Expected no error from InitConfig with valid file")
-	newPath := "./data/new_urls.txt"
-	SetFilePath(newPath)
-	assert.Equal(t, newPath, Get().DefaultFilePath, "File path should be updated to new path")
+	SetCrawlLimits(0, 0, 4)
+	assert.Equal(t, 0, Get().Crawl.MaxDepth, "Crawl depth should be updated to 0")
+	assert.Equal(t, 10, Get().Crawl.MaxPages, "Crawled pages should be kept")
+	assert.Equal(t, 4, Get().Crawl.PerHost, "Pages fetched at once from a host should be updated")
+}
+
+// Test SetFilePaths to ensure it updates the input files correctly
+func TestSetFilePathsSuccess(t *testing.T) {
+	err := InitConfig(devConfigFilePath)
+	assert.Nil(t, err, "Expected no error from InitConfig with valid file")
+	newPaths := []string{"./data/new_urls.txt", "-"}
+	SetFilePaths(newPaths)
+	assert.Equal(t, newPaths, Get().Input.Files, "Input files should be updated to new paths")
+	SetFilePaths(nil)
+	assert.Equal(t, newPaths, Get().Input.Files, "No path should keep the input files")
 }
 
 // Test SetTopN to ensure it updates the ResultLength correctly
@@ -79,3 +147,15 @@ func TestSetTopNSuccess(t *testing.T) {
 	assert.Equal(t, newLength, Get().ResultLength, "Result length should be updated to new value")
 	defer removeTestConfig()
 }
+
+// Test SetOutputFormat and SetOutputPath update the output settings
+func TestSetOutputSuccess(t *testing.T) {
+	err := InitConfig(devConfigFilePath)
+	assert.Nil(t, err, "Expected no error from InitConfig with valid file")
+	SetOutputFormat("csv")
+	SetOutputPath("./result.csv")
+	assert.Equal(t, "csv", Get().OutputFormat, "Output format should be updated")
+	assert.Equal(t, "./result.csv", Get().OutputPath, "Output path should be updated")
+	SetOutputFormat("")
+	assert.Equal(t, "csv", Get().OutputFormat, "Output format should be kept when the flag is empty")
+}
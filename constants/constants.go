@@ -4,8 +4,48 @@ const Empty = ""
 
 // Flag constants
 const (
-	FileFlagConstantName = "file"
-	TopFlagConstantName  = "top"
+	FileFlagConstantName               = "file"
+	TopFlagConstantName                = "top"
+	FormatFlagConstantName             = "format"
+	OutputFlagConstantName             = "output"
+	ExportFlagConstantName             = "export"
+	RenderFlagConstantName             = "render"
+	SeedFlagConstantName               = "seed"
+	HTMLReportFlagConstantName         = "html-report"
+	CacheDirFlagConstantName           = "cache-dir"
+	CacheTTLFlagConstantName           = "cache-ttl"
+	OfflineFlagConstantName            = "offline"
+	CheckpointFlagConstantName         = "checkpoint"
+	CheckpointIntervalFlagConstantName = "checkpoint-interval"
+	ResumeFlagConstantName             = "resume"
+	MaxDurationFlagConstantName        = "max-duration"
+	URLTimeoutFlagConstantName         = "url-timeout"
+	DocumentTimeoutFlagConstantName    = "document-timeout"
+	ProgressFlagConstantName           = "progress"
+	AddrFlagConstantName               = "addr"
+	InputFormatFlagConstantName        = "input-format"
+	ColumnFlagConstantName             = "column"
+	SitemapFlagConstantName            = "sitemap"
+	FeedFlagConstantName               = "feed"
+	PathFlagConstantName               = "path"
+	SinceFlagConstantName              = "since"
+	UntilFlagConstantName              = "until"
+	IncludeFlagConstantName            = "include"
+	ExcludeFlagConstantName            = "exclude"
+	MaxDepthFlagConstantName           = "max-depth"
+	MaxPagesFlagConstantName           = "max-pages"
+	PerHostFlagConstantName            = "per-host"
+)
+
+// Command constants
+const (
+	CountCommand          = "count"
+	CrawlCommand          = "crawl"
+	FetchCommand          = "fetch"
+	ExtractCommand        = "extract"
+	CompareCommand        = "compare"
+	ServeCommand          = "serve"
+	ValidateConfigCommand = "validate-config"
 )
 
 // ProdConfigFilePath dev path constants
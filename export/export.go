@@ -0,0 +1,127 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// Export formats, chosen from the extension of the export file
+const (
+	SQLite  = "sqlite"
+	Parquet = "parquet"
+	CSV     = "csv"
+)
+
+var extensions = map[string]string{
+	".db":      SQLite,
+	".sqlite":  SQLite,
+	".sqlite3": SQLite,
+	".parquet": Parquet,
+	".csv":     CSV,
+}
+
+// Format returns the export format matching the extension of path
+func Format(path string) (string, error) {
+	format, ok := extensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("unknown export file extension %q, expected .db, .sqlite, .sqlite3, .parquet or .csv", filepath.Ext(path))
+	}
+	return format, nil
+}
+
+// Write exports the vocabulary to path, replacing the file if it exists. SQLite
+// databases hold the words, documents and word_documents tables, Parquet and CSV
+// files the words only.
+func Write(path string, vocabulary models.Vocabulary) error {
+	format, err := Format(path)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case SQLite:
+		return writeSQLite(path, vocabulary)
+	case Parquet:
+		return writeParquet(path, vocabulary)
+	}
+	return writeCSV(path, vocabulary)
+}
+
+// csvHeader is the header of the CSV export
+var csvHeader = []string{"id", "word", "count", "documents"}
+
+// CheckReadable returns an error when path has the extension of an export whose
+// words cannot be read back, a SQLite database or a Parquet file
+func CheckReadable(path string) error {
+	if format, err := Format(path); err == nil && format != CSV {
+		return fmt.Errorf("cannot read the words of %s, only CSV exports can be read back", path)
+	}
+	return nil
+}
+
+// ReadWords reads the words of a CSV export back, ranked by their id. SQLite and
+// Parquet exports cannot be read back.
+func ReadWords(path string) ([]models.WordCount, error) {
+	if _, err := Format(path); err != nil {
+		return nil, err
+	}
+	if err := CheckReadable(path); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		return nil, fmt.Errorf("%s is not a vocabulary export, expected the header %s", path, strings.Join(csvHeader, ","))
+	}
+	words := make([]models.WordCount, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		rank, err := strconv.Atoi(row[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid id %q", path, i+2, row[0])
+		}
+		count, err := strconv.Atoi(row[2])
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: invalid count %q", path, i+2, row[2])
+		}
+		words = append(words, models.WordCount{Rank: rank, Word: row[1], Count: count})
+	}
+	return words, nil
+}
+
+// writeCSV writes the words of the vocabulary as id,word,count,documents rows
+func writeCSV(path string, vocabulary models.Vocabulary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, word := range vocabulary.Words {
+		row := []string{strconv.Itoa(word.ID), word.Word, strconv.Itoa(word.Count), strconv.Itoa(word.Documents)}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,231 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// vocabulary builds a vocabulary of the given number of words spread over 3 documents
+func vocabulary(words int) models.Vocabulary {
+	v := models.Vocabulary{Documents: []models.VocabularyDocument{
+		{ID: 1, URL: "https://example.com/1", Language: "en", Words: 10, UniqueWords: 5},
+		{ID: 2, URL: "https://example.com/" + strings.Repeat("long/", 2000), Language: "fr", Words: 4, UniqueWords: 4},
+		{ID: 3, URL: "https://example.com/3", Language: "en", Words: 1, UniqueWords: 1},
+	}}
+	for i := 1; i <= words; i++ {
+		v.Words = append(v.Words, models.VocabularyWord{ID: i, Word: fmt.Sprintf("word%d", i), Count: words - i + 1, Documents: i%3 + 1})
+		v.Occurrences = append(v.Occurrences, models.Occurrence{DocumentID: i%3 + 1, WordID: i, Count: words - i + 1})
+	}
+	return v
+}
+
+// Test Format picks the export format from the file extension
+func TestFormat(t *testing.T) {
+	for file, expected := range map[string]string{"words.db": SQLite, "words.SQLite": SQLite, "words.parquet": Parquet, "words.csv": CSV} {
+		format, err := Format(file)
+		assert.Nil(t, err, "Expected no error for %s", file)
+		assert.Equal(t, expected, format, "Format mismatch for %s", file)
+	}
+	_, err := Format("words.xlsx")
+	assert.NotNil(t, err, "Expected an error for an unknown extension")
+}
+
+// Test the SQLite database layout, large enough to need interior and overflow pages
+func TestWriteSQLite(t *testing.T) {
+	file := path.Join(t.TempDir(), "words.db")
+	assert.Nil(t, Write(file, vocabulary(50000)), "Expected no error writing the database")
+
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err, "Expected the database to be written")
+	assert.Equal(t, "SQLite format 3\x00", string(content[:16]), "Expected the SQLite header")
+	pages := int(binary.BigEndian.Uint32(content[28:]))
+	assert.Equal(t, pages*pageSize, len(content), "Expected the header to hold the number of pages")
+	assert.Equal(t, byte(leafTablePage), content[headerSize], "Expected the schema to be a leaf page")
+	assert.Equal(t, uint16(3), binary.BigEndian.Uint16(content[headerSize+3:]), "Expected 3 tables in the schema")
+}
+
+// Test records and varints are encoded as SQLite expects
+func TestRecord(t *testing.T) {
+	assert.Equal(t, []byte{0x81, 0x00}, appendVarint(nil, 128), "Varint mismatch for 128")
+	assert.Equal(t, []byte{0x7f}, appendVarint(nil, 127), "Varint mismatch for 127")
+	assert.Equal(t, 9, len(appendVarint(nil, 1<<63)), "Expected 9 bytes for the largest values")
+	expected := []byte{4, 0, 15, 6, 'a', 0, 0, 0, 0, 0, 0, 0, 7}
+	payload, err := record(nil, "a", 7)
+	assert.Nil(t, err, "Expected no error encoding the record")
+	assert.Equal(t, expected, payload, "Record mismatch")
+	_, err = record(1.5)
+	assert.EqualError(t, err, "unsupported sqlite value float64", "Expected an error for a value SQLite cannot store here")
+}
+
+// Test the Parquet file starts and ends with the magic number and its footer length
+func TestWriteParquet(t *testing.T) {
+	file := path.Join(t.TempDir(), "words.parquet")
+	assert.Nil(t, Write(file, vocabulary(300000)), "Expected no error writing the parquet file")
+
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err, "Expected the parquet file to be written")
+	assert.Equal(t, parquetMagic, string(content[:4]), "Expected the leading magic number")
+	assert.Equal(t, parquetMagic, string(content[len(content)-4:]), "Expected the trailing magic number")
+	footer := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	assert.Contains(t, string(content[len(content)-8-footer:]), "documents", "Expected the schema in the footer")
+}
+
+// Test the Parquet file is read back by decoding its footer and the pages of
+// every column chunk it points to
+func TestWriteParquetRoundTrip(t *testing.T) {
+	v := vocabulary(300000)
+	file := path.Join(t.TempDir(), "words.parquet")
+	assert.Nil(t, Write(file, v), "Expected no error writing the parquet file")
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err, "Expected the parquet file to be written")
+
+	footer := int(binary.LittleEndian.Uint32(content[len(content)-8:]))
+	metadata := (&thriftReader{b: content[len(content)-8-footer : len(content)-8]}).readStruct()
+	assert.Equal(t, int64(len(v.Words)), metadata[3], "Expected the number of rows")
+	schema := metadata[2].([]any)
+	assert.Equal(t, int64(len(parquetColumns)), schema[0].(map[int16]any)[5], "Expected the number of columns of the root")
+	rowGroups := metadata[4].([]any)
+	assert.Equal(t, 1, len(rowGroups), "Expected a single row group")
+	chunks := rowGroups[0].(map[int16]any)[1].([]any)
+	assert.Equal(t, len(parquetColumns), len(chunks), "Expected a column chunk per column")
+
+	for i, column := range parquetColumns {
+		element := schema[i+1].(map[int16]any)
+		assert.Equal(t, column.name, element[4], "Expected the name of column %d", i)
+		assert.Equal(t, int64(column.kind), element[1], "Expected the type of %s", column.name)
+		meta := chunks[i].(map[int16]any)[3].(map[int16]any)
+		assert.Equal(t, []any{column.name}, meta[3], "Expected the path of %s", column.name)
+		assert.Equal(t, int64(len(v.Words)), meta[5], "Expected the number of values of %s", column.name)
+
+		offset, size := meta[9].(int64), meta[7].(int64)
+		values, pages := readColumn(content[offset:offset+size], column.kind)
+		if column.name == "id" {
+			assert.Greater(t, pages, 1, "Expected the values of %s over several pages", column.name)
+		}
+		expected := make([]any, len(v.Words))
+		for j, w := range v.Words {
+			expected[j] = []any{int64(w.ID), w.Word, int64(w.Count), int64(w.Documents)}[i]
+		}
+		assert.True(t, assert.ObjectsAreEqual(expected, values), "Expected the values of %s to be read back", column.name)
+	}
+}
+
+// readColumn decodes the PLAIN values of the data pages of a column chunk, and
+// returns them with the number of pages
+func readColumn(chunk []byte, kind int32) ([]any, int) {
+	var values []any
+	pages := 0
+	for len(chunk) > 0 {
+		r := &thriftReader{b: chunk}
+		header := r.readStruct()
+		size := header[3].(int64)
+		n := header[5].(map[int16]any)[1].(int64)
+		page := r.b[:size]
+		chunk = r.b[size:]
+		pages++
+		for ; n > 0; n-- {
+			if kind == parquetInt64 {
+				values = append(values, int64(binary.LittleEndian.Uint64(page)))
+				page = page[8:]
+				continue
+			}
+			length := binary.LittleEndian.Uint32(page)
+			values = append(values, string(page[4:4+length]))
+			page = page[4+length:]
+		}
+	}
+	return values, pages
+}
+
+// thriftReader decodes the Thrift compact protocol, structs as maps of their
+// field ids to their values and integers as int64
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) varint() uint64 {
+	v, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header := r.b[0]
+		r.b = r.b[1:]
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(unzigzag(r.varint()))
+		}
+		fields[id] = r.readValue(header & 0x0f)
+		last = id
+	}
+}
+
+func (r *thriftReader) readValue(kind byte) any {
+	switch kind {
+	case thriftI32, thriftI64:
+		return unzigzag(r.varint())
+	case thriftBinary:
+		n := r.varint()
+		v := string(r.b[:n])
+		r.b = r.b[n:]
+		return v
+	case thriftList:
+		header := r.b[0]
+		r.b = r.b[1:]
+		size := uint64(header >> 4)
+		if size == 15 {
+			size = r.varint()
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unexpected thrift type %d", kind))
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// Test the CSV export lists every word
+func TestWriteCSV(t *testing.T) {
+	file := path.Join(t.TempDir(), "words.csv")
+	assert.Nil(t, Write(file, vocabulary(2)), "Expected no error writing the csv file")
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err, "Expected the csv file to be written")
+	assert.Equal(t, "id,word,count,documents\n1,word1,2,2\n2,word2,1,3\n", string(content), "CSV export mismatch")
+}
+
+// Test the words of a CSV export are read back with their rank
+func TestReadWords(t *testing.T) {
+	file := path.Join(t.TempDir(), "words.csv")
+	assert.Nil(t, Write(file, vocabulary(3)), "Expected no error exporting the words")
+	words, err := ReadWords(file)
+	assert.Nil(t, err, "Expected no error reading the words")
+	assert.Equal(t, []models.WordCount{{Rank: 1, Word: "word1", Count: 3}, {Rank: 2, Word: "word2", Count: 2}, {Rank: 3, Word: "word3", Count: 1}}, words, "Words mismatch")
+
+	other := path.Join(t.TempDir(), "urls.csv")
+	assert.Nil(t, os.WriteFile(other, []byte("url\nhttps://example.com\n"), 0644), "Expected no error writing the file")
+	_, err = ReadWords(other)
+	assert.NotNil(t, err, "Expected an error for a file which is not an export")
+	_, err = ReadWords(path.Join(t.TempDir(), "words.db"))
+	assert.NotNil(t, err, "Expected an error for a SQLite export")
+}
@@ -0,0 +1,293 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// The Parquet file is written following https://parquet.apache.org/docs/file-format/
+// with a single row group, uncompressed PLAIN encoded pages and required columns,
+// which needs no dependency. Its metadata is encoded with the Thrift compact protocol.
+const (
+	parquetMagic = "PAR1"
+	// maxPageBytes bounds the values buffered for a single data page
+	maxPageBytes = 1024 * 1024
+
+	parquetInt64     = 2
+	parquetByteArray = 6
+	parquetRequired  = 0
+	parquetUTF8      = 0
+	parquetPlain     = 0
+	parquetRLE       = 3
+	parquetDataPage  = 0
+)
+
+// parquetColumn is a column of the vocabulary and how to encode its values
+type parquetColumn struct {
+	name   string
+	kind   int32
+	encode func(b []byte, word models.VocabularyWord) []byte
+}
+
+var parquetColumns = []parquetColumn{
+	{"id", parquetInt64, func(b []byte, w models.VocabularyWord) []byte {
+		return binary.LittleEndian.AppendUint64(b, uint64(w.ID))
+	}},
+	{"word", parquetByteArray, func(b []byte, w models.VocabularyWord) []byte {
+		b = binary.LittleEndian.AppendUint32(b, uint32(len(w.Word)))
+		return append(b, w.Word...)
+	}},
+	{"count", parquetInt64, func(b []byte, w models.VocabularyWord) []byte {
+		return binary.LittleEndian.AppendUint64(b, uint64(w.Count))
+	}},
+	{"documents", parquetInt64, func(b []byte, w models.VocabularyWord) []byte {
+		return binary.LittleEndian.AppendUint64(b, uint64(w.Documents))
+	}},
+}
+
+// columnChunk is the position of a column written to the file
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// writeParquet writes the words of the vocabulary to a Parquet file at path
+func writeParquet(path string, vocabulary models.Vocabulary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+
+	offset := int64(len(parquetMagic))
+	if _, err := w.WriteString(parquetMagic); err != nil {
+		return err
+	}
+	chunks := make([]columnChunk, len(parquetColumns))
+	for i, column := range parquetColumns {
+		chunks[i].offset = offset
+		var values []byte
+		count := 0
+		flush := func() error {
+			page := dataPageHeader(len(values), count)
+			if _, err := w.Write(page); err != nil {
+				return err
+			}
+			if _, err := w.Write(values); err != nil {
+				return err
+			}
+			chunks[i].size += int64(len(page) + len(values))
+			values, count = values[:0], 0
+			return nil
+		}
+		for _, word := range vocabulary.Words {
+			values = column.encode(values, word)
+			count++
+			if len(values) >= maxPageBytes {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+		if count > 0 || len(vocabulary.Words) == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		offset += chunks[i].size
+	}
+
+	metadata := fileMetadata(len(vocabulary.Words), chunks)
+	if _, err := w.Write(metadata); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(metadata))); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(parquetMagic); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// dataPageHeader encodes the header of an uncompressed data page
+func dataPageHeader(size, values int) []byte {
+	var t thrift
+	t.i32(1, parquetDataPage)
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.begin(5)
+	t.i32(1, int32(values))
+	t.i32(2, parquetPlain)
+	t.i32(3, parquetRLE)
+	t.i32(4, parquetRLE)
+	t.end()
+	t.stop()
+	return t.Bytes()
+}
+
+// fileMetadata encodes the schema and the single row group of the file
+func fileMetadata(rows int, chunks []columnChunk) []byte {
+	var t thrift
+	t.i32(1, 1)
+
+	t.list(2, len(parquetColumns)+1)
+	t.element()
+	t.binary(4, "vocabulary")
+	t.i32(5, int32(len(parquetColumns)))
+	t.end()
+	for _, column := range parquetColumns {
+		t.element()
+		t.i32(1, column.kind)
+		t.i32(3, parquetRequired)
+		t.binary(4, column.name)
+		if column.kind == parquetByteArray {
+			t.i32(6, parquetUTF8)
+		}
+		t.end()
+	}
+
+	t.i64(3, int64(rows))
+
+	var total int64
+	for _, chunk := range chunks {
+		total += chunk.size
+	}
+	t.list(4, 1)
+	t.element()
+	t.list(1, len(chunks))
+	for i, chunk := range chunks {
+		t.element()
+		t.i64(2, chunk.offset)
+		t.begin(3)
+		t.i32(1, parquetColumns[i].kind)
+		t.listI32(2, parquetPlain, parquetRLE)
+		t.listBinary(3, parquetColumns[i].name)
+		t.i32(4, 0) // uncompressed
+		t.i64(5, int64(rows))
+		t.i64(6, chunk.size)
+		t.i64(7, chunk.size)
+		t.i64(9, chunk.offset)
+		t.end()
+		t.end()
+	}
+	t.i64(2, total)
+	t.i64(3, int64(rows))
+	t.end()
+
+	t.binary(6, "essay-word-counter")
+	t.stop()
+	return t.Bytes()
+}
+
+// Types of the Thrift compact protocol
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thrift encodes structs with the Thrift compact protocol. Nested structs are
+// opened with begin or element, when they are list elements, and closed with end.
+type thrift struct {
+	bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (t *thrift) field(id int16, kind byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.WriteByte(kind)
+		t.varint(zigzag(int64(id)))
+	}
+	t.last = id
+}
+
+func (t *thrift) varint(v uint64) {
+	t.Write(binary.AppendUvarint(nil, v))
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+func (t *thrift) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *thrift) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.varint(zigzag(v))
+}
+
+func (t *thrift) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.varint(uint64(len(v)))
+	t.WriteString(v)
+}
+
+// list starts a list of size structs, each one opened with element
+func (t *thrift) list(id int16, size int) {
+	t.listHeader(id, size, thriftStruct)
+}
+
+func (t *thrift) listI32(id int16, values ...int32) {
+	t.listHeader(id, len(values), thriftI32)
+	for _, v := range values {
+		t.varint(zigzag(int64(v)))
+	}
+}
+
+func (t *thrift) listBinary(id int16, values ...string) {
+	t.listHeader(id, len(values), thriftBinary)
+	for _, v := range values {
+		t.varint(uint64(len(v)))
+		t.WriteString(v)
+	}
+}
+
+func (t *thrift) listHeader(id int16, size int, kind byte) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | kind)
+		return
+	}
+	t.WriteByte(0xf0 | kind)
+	t.varint(uint64(size))
+}
+
+// begin opens a struct field
+func (t *thrift) begin(id int16) {
+	t.field(id, thriftStruct)
+	t.element()
+}
+
+// element opens a struct written as a list element
+func (t *thrift) element() {
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+// end closes the current struct
+func (t *thrift) end() {
+	t.stop()
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thrift) stop() {
+	t.WriteByte(0)
+}
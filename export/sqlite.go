@@ -0,0 +1,350 @@
+package export
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// The SQLite database is written page by page following the file format described
+// at https://www.sqlite.org/fileformat.html, so that no SQLite driver is needed.
+// Tables are rowid b-trees built bottom-up from rows inserted in rowid order.
+const (
+	pageSize = 4096
+	// sqliteVersion is the SQLite version number stored in the header
+	sqliteVersion = 3045000
+
+	leafTablePage     = 0x0d
+	interiorTablePage = 0x05
+	// headerSize is the size of the database header at the start of page 1
+	headerSize = 100
+)
+
+// sqliteTables are the tables of the database and the statements creating them
+var sqliteTables = []struct{ name, sql string }{
+	{"words", "CREATE TABLE words (id INTEGER PRIMARY KEY, word TEXT NOT NULL, count INTEGER NOT NULL, documents INTEGER NOT NULL)"},
+	{"documents", "CREATE TABLE documents (id INTEGER PRIMARY KEY, url TEXT NOT NULL, language TEXT NOT NULL, words INTEGER NOT NULL, unique_words INTEGER NOT NULL)"},
+	{"word_documents", "CREATE TABLE word_documents (document_id INTEGER NOT NULL REFERENCES documents(id), word_id INTEGER NOT NULL REFERENCES words(id), count INTEGER NOT NULL)"},
+}
+
+// writeSQLite writes the vocabulary to a new SQLite database at path
+func writeSQLite(path string, vocabulary models.Vocabulary) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// page 1 holds the header and the schema, it is written once the tables are
+	w := &sqliteWriter{f: f, pages: 1}
+	roots := make([]int, len(sqliteTables))
+
+	words := w.newTable()
+	for _, word := range vocabulary.Words {
+		if err := words.insert(int64(word.ID), nil, word.Word, word.Count, word.Documents); err != nil {
+			return err
+		}
+	}
+	documents := w.newTable()
+	for _, d := range vocabulary.Documents {
+		if err := documents.insert(int64(d.ID), nil, d.URL, d.Language, d.Words, d.UniqueWords); err != nil {
+			return err
+		}
+	}
+	occurrences := w.newTable()
+	for i, o := range vocabulary.Occurrences {
+		if err := occurrences.insert(int64(i+1), o.DocumentID, o.WordID, o.Count); err != nil {
+			return err
+		}
+	}
+	for i, table := range []*tableBuilder{words, documents, occurrences} {
+		if roots[i], err = table.finish(); err != nil {
+			return err
+		}
+	}
+
+	schema := newPage(leafTablePage, headerSize)
+	for i, table := range sqliteTables {
+		payload, err := record("table", table.name, table.name, roots[i], table.sql)
+		if err != nil {
+			return err
+		}
+		cell := tableCell(int64(i+1), payload)
+		if !schema.fits(cell) {
+			return fmt.Errorf("sqlite schema does not fit in the first page")
+		}
+		schema.add(cell)
+	}
+	page := schema.bytes(0)
+	writeHeader(page, w.pages)
+	if _, err := f.WriteAt(page, 0); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeHeader fills the database header of page 1
+func writeHeader(page []byte, pages int) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], pageSize)
+	page[18], page[19] = 1, 1 // legacy journal mode
+	page[20] = 0              // no reserved space at the end of pages
+	page[21], page[22], page[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(page[24:], 1) // file change counter
+	binary.BigEndian.PutUint32(page[28:], uint32(pages))
+	binary.BigEndian.PutUint32(page[40:], 1) // schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4) // schema format
+	binary.BigEndian.PutUint32(page[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1) // version valid for the change counter
+	binary.BigEndian.PutUint32(page[96:], sqliteVersion)
+}
+
+// sqliteWriter appends pages to the database file
+type sqliteWriter struct {
+	f     *os.File
+	pages int
+}
+
+// write appends the page and returns its number
+func (w *sqliteWriter) write(page []byte) (int, error) {
+	w.pages++
+	_, err := w.f.WriteAt(page, int64(w.pages-1)*pageSize)
+	return w.pages, err
+}
+
+// child is a page of a b-tree and the largest rowid it holds
+type child struct {
+	page  int
+	rowid int64
+}
+
+// tableBuilder writes the leaves of a table as rows are inserted and the interior
+// pages once every row has been
+type tableBuilder struct {
+	w      *sqliteWriter
+	leaf   *page
+	last   int64
+	leaves []child
+}
+
+func (w *sqliteWriter) newTable() *tableBuilder {
+	return &tableBuilder{w: w, leaf: newPage(leafTablePage, 0)}
+}
+
+// insert adds a row of the values, rowids being inserted in ascending order
+func (t *tableBuilder) insert(rowid int64, values ...any) error {
+	payload, err := record(values...)
+	if err != nil {
+		return err
+	}
+	cell, err := t.leafCell(rowid, payload)
+	if err != nil {
+		return err
+	}
+	if !t.leaf.fits(cell) {
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	t.leaf.add(cell)
+	t.last = rowid
+	return nil
+}
+
+// leafCell encodes a row, the end of a large payload going to overflow pages
+func (t *tableBuilder) leafCell(rowid int64, payload []byte) ([]byte, error) {
+	local := localPayload(len(payload))
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+	cell = append(cell, payload[:local]...)
+	if local == len(payload) {
+		return cell, nil
+	}
+	first, err := t.writeOverflow(payload[local:])
+	if err != nil {
+		return nil, err
+	}
+	return binary.BigEndian.AppendUint32(cell, uint32(first)), nil
+}
+
+// writeOverflow writes the chain of overflow pages of a payload and returns its first page
+func (t *tableBuilder) writeOverflow(payload []byte) (int, error) {
+	const capacity = pageSize - 4
+	count := (len(payload) + capacity - 1) / capacity
+	// the pages are written in order, so the next page of a chain is the following one
+	first := t.w.pages + 1
+	for i := 0; i < count; i++ {
+		page := make([]byte, pageSize)
+		if i < count-1 {
+			binary.BigEndian.PutUint32(page, uint32(first+i+1))
+		}
+		copy(page[4:], payload[i*capacity:min(len(payload), (i+1)*capacity)])
+		if _, err := t.w.write(page); err != nil {
+			return 0, err
+		}
+	}
+	return first, nil
+}
+
+// flush writes the current leaf
+func (t *tableBuilder) flush() error {
+	n, err := t.w.write(t.leaf.bytes(0))
+	if err != nil {
+		return err
+	}
+	t.leaves = append(t.leaves, child{page: n, rowid: t.last})
+	t.leaf = newPage(leafTablePage, 0)
+	return nil
+}
+
+// finish writes the last leaf and the interior pages, and returns the root page
+func (t *tableBuilder) finish() (int, error) {
+	if err := t.flush(); err != nil {
+		return 0, err
+	}
+	level := t.leaves
+	for len(level) > 1 {
+		var parents []child
+		for len(level) > 0 {
+			interior := newPage(interiorTablePage, 0)
+			i := 0
+			// every child but the last one of the page gets a cell, the last one is the right-most pointer
+			for ; i < len(level)-1; i++ {
+				cell := binary.BigEndian.AppendUint32(nil, uint32(level[i].page))
+				cell = appendVarint(cell, uint64(level[i].rowid))
+				if !interior.fits(cell) {
+					break
+				}
+				interior.add(cell)
+			}
+			n, err := t.w.write(interior.bytes(level[i].page))
+			if err != nil {
+				return 0, err
+			}
+			parents = append(parents, child{page: n, rowid: level[i].rowid})
+			level = level[i+1:]
+		}
+		level = parents
+	}
+	return level[0].page, nil
+}
+
+// page is a b-tree page being filled with cells
+type page struct {
+	kind   byte
+	offset int // start of the page header, 100 on page 1
+	cells  [][]byte
+	size   int
+}
+
+func newPage(kind byte, offset int) *page {
+	return &page{kind: kind, offset: offset}
+}
+
+func (p *page) headerSize() int {
+	if p.kind == interiorTablePage {
+		return 12
+	}
+	return 8
+}
+
+// fits reports whether the cell and its pointer fit in the page
+func (p *page) fits(cell []byte) bool {
+	return p.offset+p.headerSize()+2*(len(p.cells)+1)+p.size+len(cell) <= pageSize
+}
+
+func (p *page) add(cell []byte) {
+	p.cells = append(p.cells, cell)
+	p.size += len(cell)
+}
+
+// bytes lays out the page, the cells being stored from the end of the page
+func (p *page) bytes(rightMost int) []byte {
+	b := make([]byte, pageSize)
+	h := b[p.offset:]
+	h[0] = p.kind
+	binary.BigEndian.PutUint16(h[3:], uint16(len(p.cells)))
+	if p.kind == interiorTablePage {
+		binary.BigEndian.PutUint32(h[8:], uint32(rightMost))
+	}
+	content := pageSize
+	for i, cell := range p.cells {
+		content -= len(cell)
+		copy(b[content:], cell)
+		binary.BigEndian.PutUint16(h[p.headerSize()+2*i:], uint16(content))
+	}
+	// a content area starting at 65536 is stored as 0, which never happens with 4096 byte pages
+	binary.BigEndian.PutUint16(h[5:], uint16(content))
+	return b
+}
+
+// tableCell encodes a row stored entirely in the page
+func tableCell(rowid int64, payload []byte) []byte {
+	cell := appendVarint(nil, uint64(len(payload)))
+	cell = appendVarint(cell, uint64(rowid))
+	return append(cell, payload...)
+}
+
+// localPayload returns how many bytes of a payload are stored in a table leaf cell
+func localPayload(size int) int {
+	const (
+		maxLocal = pageSize - 35
+		minLocal = (pageSize-12)*32/255 - 23
+	)
+	if size <= maxLocal {
+		return size
+	}
+	local := minLocal + (size-minLocal)%(pageSize-4)
+	if local > maxLocal {
+		return minLocal
+	}
+	return local
+}
+
+// record encodes the values of a row, nil being stored as NULL, which is how an
+// INTEGER PRIMARY KEY column holding the rowid is stored
+func record(values ...any) ([]byte, error) {
+	var header, body []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			header = appendVarint(header, 0)
+		case string:
+			header = appendVarint(header, uint64(2*len(v)+13))
+			body = append(body, v...)
+		case int:
+			header = appendVarint(header, 6)
+			body = binary.BigEndian.AppendUint64(body, uint64(v))
+		default:
+			return nil, fmt.Errorf("unsupported sqlite value %T", value)
+		}
+	}
+	// the header size includes its own varint, which is a single byte for a few columns
+	size := appendVarint(nil, uint64(len(header)+1))
+	return append(append(size, header...), body...), nil
+}
+
+// appendVarint appends v in the big-endian variable-length encoding of SQLite
+func appendVarint(b []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		// the 9th byte holds 8 bits
+		var buf [9]byte
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return append(b, buf[:]...)
+	}
+	var buf [8]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & 0x7f)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		buf[i] = byte(v&0x7f) | 0x80
+	}
+	return append(b, buf[i:]...)
+}
@@ -11,21 +11,65 @@ import (
 	"github.com/joshy-joy/essay-word-counter/config"
 )
 
+// Response is a page fetched by Fetch
+type Response struct {
+	// Body is nil when the page has not been modified
+	Body        io.ReadCloser
+	Header      http.Header
+	NotModified bool
+}
+
 func FetchEssay(ctx context.Context, method, url string) (io.ReadCloser, error) {
+	resp, err := do(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("non-200 status code %d for URL %s", resp.StatusCode, url)
+	}
+	return resp.Body, nil
+}
+
+// Fetch gets the page of url. When the etag or the last modification of a cached
+// copy is given, the request is conditional and a page which has not changed is
+// not sent again.
+func Fetch(ctx context.Context, url, etag, lastModified string) (Response, error) {
+	header := make(http.Header)
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		header.Set("If-Modified-Since", lastModified)
+	}
+	resp, err := do(ctx, http.MethodGet, url, header)
+	if err != nil {
+		return Response{}, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return Response{Body: resp.Body, Header: resp.Header}, nil
+	case http.StatusNotModified:
+		resp.Body.Close()
+		return Response{Header: resp.Header, NotModified: true}, nil
+	}
+	resp.Body.Close()
+	return Response{}, fmt.Errorf("non-200 status code %d for URL %s", resp.StatusCode, url)
+}
+
+func do(ctx context.Context, method, url string, header http.Header) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		return nil, err
 	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
 	client := &http.Client{Timeout: time.Duration(config.Get().External.Timeout) * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
 		log.Printf("Failed to fetch URL %s: %v", url, err)
 		return nil, err
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("non-200 status code %d for URL %s", resp.StatusCode, url)
-	}
-
-	return resp.Body, nil
+	return resp, nil
 }
@@ -60,3 +60,31 @@ func TestFetchEssayErrorEmptyURL(t *testing.T) {
 	assert.NotNil(t, err, "Expected an error due to empty URL")
 	assert.Nil(t, resp, "Expected nil response for empty URL")
 }
+
+// Test Fetch sends the validators of the cached copy and reports a page which has not changed
+func TestFetchConditional(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` && r.Header.Get("If-Modified-Since") == "Sun, 25 Aug 2019 10:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("This is a test response"))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	resp, err := Fetch(ctx, server.URL, "", "")
+	assert.Nil(t, err, "Expected no error for valid request")
+	assert.False(t, resp.NotModified, "Expected the page without validators")
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "This is a test response", string(body), "Response body should match expected content")
+	assert.Equal(t, `"v1"`, resp.Header.Get("ETag"), "Expected the headers of the response")
+
+	resp, err = Fetch(ctx, server.URL, `"v1"`, "Sun, 25 Aug 2019 10:00:00 GMT")
+	assert.Nil(t, err, "Expected no error for a page which has not changed")
+	assert.True(t, resp.NotModified, "Expected the page not to be modified")
+	assert.Nil(t, resp.Body, "Expected no body for a page which has not changed")
+}
@@ -0,0 +1,84 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+)
+
+// DOCX streams the paragraphs of the body of a Word document
+type DOCX struct {
+	paragraphs
+	body    io.ReadCloser
+	decoder *xml.Decoder
+	// inText is set inside a run of text, the only element whose content is read
+	inText bool
+	done   bool
+}
+
+// NewDOCX creates an extractor reading the Word document of the given size from r
+func NewDOCX(r io.ReaderAt, size int64) (*DOCX, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range archive.File {
+		if f.Name == "word/document.xml" {
+			body, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			return &DOCX{body: body, decoder: xml.NewDecoder(body)}, nil
+		}
+	}
+	return nil, errors.New("docx has no word/document.xml")
+}
+
+// Next returns the next paragraph of the document, or io.EOF once it has been read
+func (e *DOCX) Next() (string, error) {
+	for len(e.ready) == 0 {
+		if e.done {
+			return "", io.EOF
+		}
+		if err := e.step(); err != nil {
+			return "", err
+		}
+	}
+	return e.next(), nil
+}
+
+// step reads the next xml token of the document
+func (e *DOCX) step() error {
+	token, err := e.decoder.Token()
+	if err == io.EOF {
+		e.flush()
+		e.done = true
+		return e.body.Close()
+	}
+	if err != nil {
+		e.body.Close()
+		return err
+	}
+	switch t := token.(type) {
+	case xml.StartElement:
+		switch t.Name.Local {
+		case "t":
+			e.inText = true
+		case "tab", "br", "cr":
+			e.write(" ")
+		}
+	case xml.EndElement:
+		switch t.Name.Local {
+		case "t":
+			e.inText = false
+		case "p":
+			e.flush()
+		}
+	case xml.CharData:
+		if e.inText {
+			e.write(string(t))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package extract
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+)
+
+// EPUB streams the paragraphs of the chapters of an EPUB book, in reading order
+type EPUB struct {
+	chapters []*zip.File
+	current  Extractor
+	body     io.ReadCloser
+}
+
+// NewEPUB creates an extractor reading the EPUB book of the given size from r
+func NewEPUB(r io.ReaderAt, size int64) (*EPUB, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := decodeXML(files, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, errors.New("epub has no package document")
+	}
+	opf := container.Rootfiles[0].FullPath
+
+	var pkg struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := decodeXML(files, opf, &pkg); err != nil {
+		return nil, err
+	}
+	hrefs := make(map[string]string, len(pkg.Items))
+	for _, item := range pkg.Items {
+		hrefs[item.ID] = item.Href
+	}
+	e := &EPUB{}
+	for _, ref := range pkg.Spine {
+		href, err := url.PathUnescape(hrefs[ref.IDRef])
+		if err != nil {
+			return nil, err
+		}
+		chapter, ok := files[path.Join(path.Dir(opf), href)]
+		if !ok {
+			return nil, fmt.Errorf("epub has no chapter %q", hrefs[ref.IDRef])
+		}
+		e.chapters = append(e.chapters, chapter)
+	}
+	return e, nil
+}
+
+// decodeXML decodes the xml file of the archive named name into v
+func decodeXML(files map[string]*zip.File, name string, v any) error {
+	f, ok := files[name]
+	if !ok {
+		return fmt.Errorf("archive has no %s", name)
+	}
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return xml.NewDecoder(r).Decode(v)
+}
+
+// Next returns the next paragraph of the book, or io.EOF once every chapter has been read
+func (e *EPUB) Next() (string, error) {
+	for {
+		if e.current == nil {
+			if len(e.chapters) == 0 {
+				return "", io.EOF
+			}
+			body, err := e.chapters[0].Open()
+			if err != nil {
+				return "", err
+			}
+			e.chapters = e.chapters[1:]
+			e.body, e.current = body, NewHTML(body)
+		}
+		paragraph, err := e.current.Next()
+		if err != io.EOF {
+			return paragraph, err
+		}
+		e.body.Close()
+		e.current = nil
+	}
+}
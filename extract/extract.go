@@ -0,0 +1,133 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// Formats of the documents, each one read by its own extractor
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatEPUB     = "epub"
+	FormatDOCX     = "docx"
+	FormatPDF      = "pdf"
+)
+
+// extensions maps the file extensions to their format
+var extensions = map[string]string{
+	".txt":      FormatText,
+	".text":     FormatText,
+	".md":       FormatMarkdown,
+	".markdown": FormatMarkdown,
+	".html":     FormatHTML,
+	".htm":      FormatHTML,
+	".xhtml":    FormatHTML,
+	".epub":     FormatEPUB,
+	".docx":     FormatDOCX,
+	".pdf":      FormatPDF,
+}
+
+// FormatOf returns the format of a file from its extension, empty when it is not supported
+func FormatOf(path string) string {
+	return extensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// MaxParagraphBytes bounds the text buffered for a single paragraph, longer
+// paragraphs are cut at the last space before the limit
+const MaxParagraphBytes = 64 * 1024
+
+// Extractor streams the readable text of a document one paragraph at a time
+type Extractor interface {
+	// Next returns the next paragraph, or io.EOF once the document has been read
+	Next() (string, error)
+}
+
+// New creates the extractor of the format reading the document from r. Pages
+// fetched from the web have the html format, an empty format is html too.
+func New(format string, r io.Reader) (Extractor, error) {
+	switch format {
+	case "", FormatHTML:
+		return NewHTML(r), nil
+	case FormatText:
+		return NewText(r), nil
+	case FormatMarkdown:
+		return NewMarkdown(r), nil
+	}
+
+	// archives and pdf documents are read at random
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	reader := bytes.NewReader(data)
+	switch format {
+	case FormatEPUB:
+		return NewEPUB(reader, reader.Size())
+	case FormatDOCX:
+		return NewDOCX(reader, reader.Size())
+	case FormatPDF:
+		return NewPDF(reader, reader.Size())
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// paragraphs collects the text of a document into paragraphs, collapsing the
+// white spaces
+type paragraphs struct {
+	paragraph strings.Builder
+	ready     []string
+	// space is set when a white space is pending before the next character
+	space bool
+}
+
+// write appends text to the current paragraph, collapsing the white spaces
+func (p *paragraphs) write(text string) {
+	for _, r := range text {
+		if unicode.IsSpace(r) {
+			p.space = p.paragraph.Len() > 0
+			continue
+		}
+		if p.space {
+			p.paragraph.WriteByte(' ')
+			p.space = false
+		}
+		p.paragraph.WriteRune(r)
+		if p.paragraph.Len() >= MaxParagraphBytes {
+			p.cut()
+		}
+	}
+}
+
+// cut emits the text of an oversized paragraph up to its last space
+func (p *paragraphs) cut() {
+	text := p.paragraph.String()
+	idx := strings.LastIndexByte(text, ' ')
+	if idx <= 0 {
+		idx = len(text)
+	}
+	p.ready = append(p.ready, text[:idx])
+	p.paragraph.Reset()
+	p.paragraph.WriteString(strings.TrimLeft(text[idx:], " "))
+}
+
+// flush ends the current paragraph
+func (p *paragraphs) flush() {
+	if p.paragraph.Len() > 0 {
+		p.ready = append(p.ready, p.paragraph.String())
+	}
+	p.paragraph.Reset()
+	p.space = false
+}
+
+// next returns the first paragraph ready, there must be one
+func (p *paragraphs) next() string {
+	paragraph := p.ready[0]
+	p.ready = p.ready[1:]
+	return paragraph
+}
@@ -0,0 +1,122 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helper reading every paragraph of an extractor
+func readAll(t *testing.T, e Extractor) []string {
+	var paragraphs []string
+	for {
+		paragraph, err := e.Next()
+		if err == io.EOF {
+			return paragraphs
+		}
+		assert.Nil(t, err, "Expected no error while extracting")
+		paragraphs = append(paragraphs, paragraph)
+	}
+}
+
+// Test the HTML extractor splits the text at block elements
+func TestHTMLParagraphs(t *testing.T) {
+	page := `<html><head><title>Title</title><style>p { color: red; }</style></head>
+		<body><div><p>Hello</p> <span>world!</span> <b>Bye</b></div>
+		<script>var x = "ignored";</script><ul><li>First &amp; <i>only</i>
+		item</li></ul>Tail<br>end</body></html>`
+	expected := []string{"Hello", "world! Bye", "First & only item", "Tail", "end"}
+	assert.Equal(t, expected, readAll(t, NewHTML(strings.NewReader(page))), "Extracted paragraphs are incorrect")
+}
+
+// Test the HTML extractor keeps words split by inline elements together
+func TestHTMLInlineElements(t *testing.T) {
+	page := "<p>Wor<b>ds</b> stay <em>whole</em>.</p>"
+	assert.Equal(t, []string{"Words stay whole."}, readAll(t, NewHTML(strings.NewReader(page))), "Expected inline elements not to split words")
+}
+
+// Test the HTML extractor cuts oversized paragraphs at a space
+func TestHTMLLongParagraph(t *testing.T) {
+	word := strings.Repeat("a", 99) + " "
+	page := "<p>" + strings.Repeat(word, 2*MaxParagraphBytes/len(word)) + "</p>"
+	paragraphs := readAll(t, NewHTML(strings.NewReader(page)))
+
+	assert.Equal(t, 2, len(paragraphs), "Expected the paragraph to be cut")
+	for _, paragraph := range paragraphs {
+		assert.LessOrEqual(t, len(paragraph), MaxParagraphBytes, "Expected paragraphs to be bounded")
+		assert.False(t, strings.HasPrefix(paragraph, " ") || strings.HasSuffix(paragraph, " "), "Expected paragraphs to be cut at a space")
+	}
+}
+
+// Test the text extractor splits the paragraphs at blank lines
+func TestText(t *testing.T) {
+	document := "First line\nof the first paragraph.\r\n\n  \nSecond   paragraph"
+	expected := []string{"First line of the first paragraph.", "Second paragraph"}
+	assert.Equal(t, expected, readAll(t, NewText(strings.NewReader(document))), "Extracted paragraphs are incorrect")
+}
+
+// Test the Markdown extractor removes the markup, code blocks and front matter
+func TestMarkdown(t *testing.T) {
+	document := "---\ntitle: Ignored\n---\n# The *title* #\n\nSome **bold** and [linked](https://example.com) text\nwith `code` and ![an image](image.png).\n\n" +
+		"```go\nfunc ignored() {}\n```\n- first item\n- second_item\n\n> quoted\n\n| a | b |\n|---|---|\n| c | d |\n\n***\n<https://example.com>"
+	expected := []string{"The title", "Some bold and linked text with code and an image.", "first item", "second_item", "quoted", "a b", "c d"}
+	assert.Equal(t, expected, readAll(t, NewMarkdown(strings.NewReader(document))), "Extracted paragraphs are incorrect")
+}
+
+// helper building a zip archive of the files
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		assert.Nil(t, err, "Expected no error while creating the archive")
+		_, err = f.Write([]byte(content))
+		assert.Nil(t, err, "Expected no error while writing the archive")
+	}
+	assert.Nil(t, w.Close(), "Expected no error while closing the archive")
+	return buf.Bytes()
+}
+
+// Test the EPUB extractor reads the chapters in the order of the spine
+func TestEPUB(t *testing.T) {
+	book := zipFiles(t, map[string]string{
+		"mimetype":               "application/epub+zip",
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><manifest><item id="one" href="one.xhtml"/><item id="two" href="text/two%20b.xhtml"/></manifest>` +
+			`<spine><itemref idref="two"/><itemref idref="one"/></spine></package>`,
+		"OEBPS/one.xhtml":        "<html><body><p>Chapter one</p></body></html>",
+		"OEBPS/text/two b.xhtml": "<html><body><h1>Two</h1><p>Chapter two</p></body></html>",
+	})
+	e, err := New(FormatEPUB, bytes.NewReader(book))
+	assert.Nil(t, err, "Expected no error while opening the book")
+	assert.Equal(t, []string{"Two", "Chapter two", "Chapter one"}, readAll(t, e), "Extracted paragraphs are incorrect")
+
+	_, err = New(FormatEPUB, bytes.NewReader(zipFiles(t, map[string]string{"mimetype": "application/epub+zip"})))
+	assert.NotNil(t, err, "Expected an error for a book without container")
+}
+
+// Test the DOCX extractor reads the runs of text of the paragraphs
+func TestDOCX(t *testing.T) {
+	document := zipFiles(t, map[string]string{
+		"word/document.xml": `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+			`<w:p><w:r><w:t>Wor</w:t></w:r><w:r><w:t>ds</w:t><w:tab/><w:t xml:space="preserve">stay </w:t></w:r><w:r><w:t>whole</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr></w:p><w:p><w:r><w:t>Second</w:t><w:br/><w:t>paragraph</w:t></w:r></w:p></w:body></w:document>`,
+	})
+	e, err := New(FormatDOCX, bytes.NewReader(document))
+	assert.Nil(t, err, "Expected no error while opening the document")
+	assert.Equal(t, []string{"Words stay whole", "Second paragraph"}, readAll(t, e), "Extracted paragraphs are incorrect")
+}
+
+// Test the format of a file is detected from its extension
+func TestFormatOf(t *testing.T) {
+	assert.Equal(t, FormatMarkdown, FormatOf("notes/README.MD"), "Expected the extension to be case insensitive")
+	assert.Equal(t, FormatPDF, FormatOf("essay.pdf"), "Expected the pdf format")
+	assert.Equal(t, "", FormatOf("image.png"), "Expected no format for unsupported files")
+
+	_, err := New("png", strings.NewReader(""))
+	assert.NotNil(t, err, "Expected an error for an unsupported format")
+}
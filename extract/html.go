@@ -0,0 +1,88 @@
+package extract
+
+import (
+	"io"
+
+	"golang.org/x/net/html"
+)
+
+// maxTokenBytes bounds the memory used by the html tokenizer for a single token
+const maxTokenBytes = 1024 * 1024
+
+// blockElements end a paragraph, so sentences never span two of them
+var blockElements = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true, "br": true,
+	"dd": true, "details": true, "div": true, "dl": true, "dt": true, "figcaption": true,
+	"figure": true, "footer": true, "form": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true, "section": true,
+	"table": true, "td": true, "th": true, "tr": true, "ul": true,
+}
+
+// skippedElements hold no readable text
+var skippedElements = map[string]bool{
+	"head": true, "script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true,
+}
+
+// HTML streams the readable text of an html page, one paragraph at a time,
+// without holding the whole page in memory
+type HTML struct {
+	paragraphs
+	z *html.Tokenizer
+	// skipDepth counts the open elements whose text is ignored
+	skipDepth int
+	done      bool
+}
+
+// NewHTML creates an extractor reading the html page from r
+func NewHTML(r io.Reader) *HTML {
+	z := html.NewTokenizer(r)
+	z.SetMaxBuf(maxTokenBytes)
+	return &HTML{z: z}
+}
+
+// Next returns the next paragraph of text, or io.EOF once the page has been read
+func (e *HTML) Next() (string, error) {
+	for len(e.ready) == 0 {
+		if e.done {
+			return "", io.EOF
+		}
+		if err := e.step(); err != nil {
+			return "", err
+		}
+	}
+	return e.next(), nil
+}
+
+// step reads the next html token
+func (e *HTML) step() error {
+	tt := e.z.Next()
+	switch tt {
+	case html.ErrorToken:
+		if e.z.Err() != io.EOF {
+			return e.z.Err()
+		}
+		e.flush()
+		e.done = true
+	case html.TextToken:
+		if e.skipDepth == 0 {
+			e.write(string(e.z.Text()))
+		}
+	case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+		name, _ := e.z.TagName()
+		tag := string(name)
+		if skippedElements[tag] {
+			switch {
+			case tt == html.StartTagToken:
+				e.skipDepth++
+			case tt == html.EndTagToken && e.skipDepth > 0:
+				e.skipDepth--
+			}
+		}
+		if blockElements[tag] {
+			e.flush()
+		}
+	}
+	return nil
+}
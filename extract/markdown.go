@@ -0,0 +1,116 @@
+package extract
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading     = regexp.MustCompile(`^ {0,3}#{1,6}(\s+|$)`)
+	markdownClosingHash = regexp.MustCompile(`\s+#+\s*$`)
+	markdownListItem    = regexp.MustCompile(`^\s*([-*+]|\d{1,9}[.)])\s+`)
+	markdownRule        = regexp.MustCompile(`^ {0,3}(([-*_=])\s*){3,}$`)
+	markdownFence       = regexp.MustCompile("^ {0,3}(```|~~~)")
+	markdownTableRule   = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+	markdownImage       = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink        = regexp.MustCompile(`\[([^\]]*)\](\([^)]*\)|\[[^\]]*\])`)
+	markdownAutoLink    = regexp.MustCompile(`<(https?|mailto):[^>]*>`)
+	markdownTag         = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	markdownEmphasis    = regexp.MustCompile("(\\*{1,3}|~~|`+)")
+	markdownUnderscores = regexp.MustCompile(`(^|\W)_{1,3}|_{1,3}(\W|$)`)
+)
+
+// Markdown streams the paragraphs of a Markdown document, without its markup,
+// code blocks and front matter. Headings and list items are paragraphs of their own.
+type Markdown struct {
+	paragraphs
+	lines
+	// fence is the fence of the code block being skipped
+	fence       string
+	frontMatter bool
+	first       bool
+}
+
+// NewMarkdown creates an extractor reading the Markdown document from r
+func NewMarkdown(r io.Reader) *Markdown {
+	return &Markdown{lines: newLines(r), first: true}
+}
+
+// Next returns the next paragraph of text, or io.EOF once the document has been read
+func (e *Markdown) Next() (string, error) {
+	for len(e.ready) == 0 {
+		line, ok, err := e.line()
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			e.flush()
+			if len(e.ready) == 0 {
+				return "", io.EOF
+			}
+			break
+		}
+		e.add(line)
+	}
+	return e.next(), nil
+}
+
+// add adds the text of a line to the paragraphs
+func (e *Markdown) add(line string) {
+	first := e.first
+	e.first = false
+	switch {
+	case first && strings.TrimSpace(line) == "---":
+		e.frontMatter = true
+		return
+	case e.frontMatter:
+		e.frontMatter = strings.TrimSpace(line) != "---" && strings.TrimSpace(line) != "..."
+		return
+	case e.fence != "":
+		if strings.HasPrefix(strings.TrimSpace(line), e.fence) {
+			e.fence = ""
+		}
+		return
+	}
+
+	if m := markdownFence.FindStringSubmatch(line); m != nil {
+		e.flush()
+		e.fence = m[1]
+		return
+	}
+	// quotes are paragraphs like the others
+	trimmed := strings.TrimSpace(line)
+	for strings.HasPrefix(trimmed, ">") {
+		trimmed = strings.TrimSpace(trimmed[1:])
+	}
+	switch {
+	case trimmed == "" || markdownRule.MatchString(trimmed) || markdownTableRule.MatchString(trimmed) && strings.Contains(trimmed, "-"):
+		e.flush()
+	case markdownHeading.MatchString(trimmed):
+		e.flush()
+		e.write(inline(markdownClosingHash.ReplaceAllString(markdownHeading.ReplaceAllString(trimmed, ""), "")))
+		e.flush()
+	case markdownListItem.MatchString(trimmed):
+		e.flush()
+		e.write(inline(markdownListItem.ReplaceAllString(trimmed, "")) + "\n")
+	case strings.HasPrefix(trimmed, "|"):
+		// every row of a table is a paragraph
+		e.flush()
+		e.write(inline(strings.ReplaceAll(trimmed, "|", " ")))
+		e.flush()
+	default:
+		e.write(inline(trimmed) + "\n")
+	}
+}
+
+// inline removes the inline markup of the text, keeping the text of links and images
+func inline(text string) string {
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownAutoLink.ReplaceAllString(text, "")
+	text = markdownTag.ReplaceAllString(text, "")
+	text = markdownEmphasis.ReplaceAllString(text, "")
+	text = markdownUnderscores.ReplaceAllString(text, "$1$2")
+	return strings.ReplaceAll(text, `\`, "")
+}
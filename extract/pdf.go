@@ -0,0 +1,888 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// The PDF extractor reads the text layer of a document: it finds the objects by
+// scanning the file rather than through the cross-reference table, so that
+// damaged files and object streams are read alike, and decodes the text shown
+// by the content stream of every page with the ToUnicode map of its font.
+
+// pdfName is a name object, without its leading slash
+type pdfName string
+
+// pdfRef is a reference to an indirect object
+type pdfRef struct{ num, gen int }
+
+// pdfDict is a dictionary object
+type pdfDict map[pdfName]any
+
+// pdfStream is a stream object, whose data is still encoded
+type pdfStream struct {
+	dict pdfDict
+	data []byte
+}
+
+// pdfKeyword is an operator of a content stream, or a keyword such as obj
+type pdfKeyword string
+
+var pdfObjectStart = regexp.MustCompile(`(?m)(\d+)\s+(\d+)\s+obj\b`)
+
+// PDF streams the paragraphs of the text layer of a PDF document, one page at a time
+type PDF struct {
+	paragraphs
+	doc   *pdfDocument
+	pages []pdfDict
+}
+
+// NewPDF creates an extractor reading the PDF document of the given size from r
+func NewPDF(r io.ReaderAt, size int64) (*PDF, error) {
+	data := make([]byte, size)
+	if _, err := r.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return nil, errors.New("not a pdf document")
+	}
+	doc := &pdfDocument{objects: make(map[int]any)}
+	doc.scan(data)
+	if doc.encrypted {
+		return nil, errors.New("encrypted pdf documents are not supported")
+	}
+	var catalog pdfDict
+	for _, o := range doc.objects {
+		if d, ok := o.(pdfDict); ok && d["Type"] == pdfName("Catalog") {
+			catalog = d
+		}
+	}
+	if catalog == nil {
+		return nil, errors.New("pdf document has no catalog")
+	}
+	e := &PDF{doc: doc}
+	e.pages = doc.pages(catalog["Pages"], nil, 0)
+	return e, nil
+}
+
+// Next returns the next paragraph of the document, or io.EOF once every page has been read
+func (e *PDF) Next() (string, error) {
+	for len(e.ready) == 0 {
+		if len(e.pages) == 0 {
+			return "", io.EOF
+		}
+		page := e.pages[0]
+		e.pages = e.pages[1:]
+		text, err := e.doc.text(page)
+		if err != nil {
+			return "", err
+		}
+		for _, paragraph := range strings.Split(text, "\n\n") {
+			e.write(pdfHyphen.ReplaceAllString(paragraph, "$1$2"))
+			e.flush()
+		}
+	}
+	return e.next(), nil
+}
+
+// pdfHyphen is a word hyphenated at the end of a line
+var pdfHyphen = regexp.MustCompile(`(\pL)-\n(\p{Ll})`)
+
+// pdfDocument holds the objects of a document by number
+type pdfDocument struct {
+	objects   map[int]any
+	encrypted bool
+}
+
+// scan parses every object of the file, the last definition of a number
+// replacing the previous ones, then the objects of the object streams
+func (d *pdfDocument) scan(data []byte) {
+	for _, m := range pdfObjectStart.FindAllSubmatchIndex(data, -1) {
+		if m[0] > 0 && !isPDFSpace(data[m[0]-1]) {
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[m[2]:m[3]]))
+		l := &pdfLexer{data: data, pos: m[1]}
+		o, err := l.object()
+		if err != nil {
+			continue
+		}
+		if dict, ok := o.(pdfDict); ok {
+			if stream, ok := l.stream(dict); ok {
+				o = stream
+			}
+		}
+		d.objects[num] = o
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		d.encrypted = true
+	}
+
+	for _, o := range d.objects {
+		stream, ok := o.(*pdfStream)
+		if !ok || stream.dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		data, err := d.decode(stream)
+		if err != nil {
+			continue
+		}
+		// every object takes at least 4 bytes of the header, its number, its offset and their separators
+		first, n := d.int(stream.dict["First"]), d.int(stream.dict["N"])
+		if n <= 0 || first < 0 || first > len(data) || n > len(data)/4+1 {
+			continue
+		}
+		l := &pdfLexer{data: data}
+		header := make([]int, 2*n)
+		for i := range header {
+			n, err := l.object()
+			if err != nil {
+				break
+			}
+			header[i] = d.int(n)
+		}
+		for i := 0; i < len(header); i += 2 {
+			if _, ok := d.objects[header[i]]; ok || header[i+1] < 0 || first+header[i+1] >= len(data) {
+				continue
+			}
+			l := &pdfLexer{data: data, pos: first + header[i+1]}
+			if o, err := l.object(); err == nil {
+				d.objects[header[i]] = o
+			}
+		}
+	}
+}
+
+// resolve follows the references to the object they refer to
+func (d *pdfDocument) resolve(o any) any {
+	for i := 0; i < 32; i++ {
+		ref, ok := o.(pdfRef)
+		if !ok {
+			return o
+		}
+		o = d.objects[ref.num]
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(o any) pdfDict {
+	switch v := d.resolve(o).(type) {
+	case pdfDict:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+func (d *pdfDocument) int(o any) int {
+	if f, ok := d.resolve(o).(float64); ok {
+		return int(f)
+	}
+	return 0
+}
+
+// pages returns the pages of the page tree in order, each one with the
+// resources it inherits
+func (d *pdfDocument) pages(node any, resources any, depth int) []pdfDict {
+	dict := d.dict(node)
+	if dict == nil || depth > 64 {
+		return nil
+	}
+	if r, ok := dict["Resources"]; ok {
+		resources = r
+	}
+	if dict["Type"] == pdfName("Page") || dict["Kids"] == nil {
+		page := pdfDict{"Contents": dict["Contents"], "Resources": resources}
+		return []pdfDict{page}
+	}
+	kids, _ := d.resolve(dict["Kids"]).([]any)
+	var pages []pdfDict
+	for _, kid := range kids {
+		pages = append(pages, d.pages(kid, resources, depth+1)...)
+	}
+	return pages
+}
+
+// decode returns the decoded data of the stream
+func (d *pdfDocument) decode(s *pdfStream) ([]byte, error) {
+	var filters []any
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []any{f}
+	case []any:
+		filters = f
+	}
+	data := s.data
+	for _, f := range filters {
+		switch d.resolve(f) {
+		case pdfName("FlateDecode"):
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			// damaged streams are often complete but for their checksum
+			decoded, err := io.ReadAll(r)
+			if err != nil && len(decoded) == 0 {
+				return nil, err
+			}
+			data = decoded
+		default:
+			return nil, fmt.Errorf("unsupported pdf filter %v", f)
+		}
+	}
+	return data, nil
+}
+
+// text returns the text shown by the page, its lines separated by a line break
+// and its paragraphs by a blank line
+func (d *pdfDocument) text(page pdfDict) (string, error) {
+	var content []byte
+	contents := d.resolve(page["Contents"])
+	streams, ok := contents.([]any)
+	if !ok {
+		streams = []any{contents}
+	}
+	for _, s := range streams {
+		stream, ok := d.resolve(s).(*pdfStream)
+		if !ok {
+			continue
+		}
+		data, err := d.decode(stream)
+		if err != nil {
+			return "", err
+		}
+		content = append(append(content, data...), '\n')
+	}
+
+	fonts := make(map[pdfName]*pdfFont)
+	for name, ref := range d.dict(d.dict(page["Resources"])["Font"]) {
+		fonts[name] = d.font(d.dict(ref))
+	}
+	return showText(content, fonts), nil
+}
+
+// pdfFont decodes the strings shown with a font
+type pdfFont struct {
+	// widths are the sizes of the codes of the code space, one byte by default
+	widths    []int
+	toUnicode map[string]string
+	// composite fonts cannot be decoded without a ToUnicode map
+	composite  bool
+	difference map[byte]string
+}
+
+// font reads the ToUnicode map and the encoding differences of the font
+func (d *pdfDocument) font(dict pdfDict) *pdfFont {
+	f := &pdfFont{composite: dict["Subtype"] == pdfName("Type0")}
+	if stream, ok := d.resolve(dict["ToUnicode"]).(*pdfStream); ok {
+		if data, err := d.decode(stream); err == nil {
+			f.parseCMap(data)
+		}
+	}
+	if encoding := d.dict(dict["Encoding"]); encoding != nil {
+		differences, _ := d.resolve(encoding["Differences"]).([]any)
+		code := 0
+		for _, o := range differences {
+			switch v := d.resolve(o).(type) {
+			case float64:
+				code = int(v)
+			case pdfName:
+				if f.difference == nil {
+					f.difference = make(map[byte]string)
+				}
+				if code >= 0 && code < 256 {
+					f.difference[byte(code)] = glyphText(string(v))
+				}
+				code++
+			}
+		}
+	}
+	return f
+}
+
+// parseCMap reads the code space and the bfchar and bfrange mappings of a ToUnicode map
+func (f *pdfFont) parseCMap(data []byte) {
+	f.toUnicode = make(map[string]string)
+	l := &pdfLexer{data: data}
+	var operands []any
+	widths := make(map[int]bool)
+	for {
+		o, err := l.object()
+		if err != nil {
+			break
+		}
+		keyword, ok := o.(pdfKeyword)
+		if !ok {
+			operands = append(operands, o)
+			continue
+		}
+		switch keyword {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if lo, ok := operands[i].(string); ok {
+					widths[len(lo)] = true
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, _ := operands[i].(string)
+				dst, _ := operands[i+1].(string)
+				f.toUnicode[src] = utf16BE(dst)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, _ := operands[i].(string)
+				hi, _ := operands[i+1].(string)
+				f.addRange(lo, hi, operands[i+2])
+			}
+		}
+		if strings.HasPrefix(string(keyword), "begin") || strings.HasPrefix(string(keyword), "end") {
+			operands = operands[:0]
+		}
+	}
+	for w := 4; w >= 1; w-- {
+		if widths[w] {
+			f.widths = append(f.widths, w)
+		}
+	}
+}
+
+// addRange maps the codes from lo to hi, to consecutive characters or to the
+// strings of an array
+func (f *pdfFont) addRange(lo, hi string, dst any) {
+	if len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+		return
+	}
+	start, end := codeValue(lo), codeValue(hi)
+	if end < start || end-start > 0xffff {
+		return
+	}
+	for code := start; code <= end; code++ {
+		key := codeString(code, len(lo))
+		switch v := dst.(type) {
+		case string:
+			runes := []rune(utf16BE(v))
+			if len(runes) == 0 {
+				return
+			}
+			runes[len(runes)-1] += rune(code - start)
+			f.toUnicode[key] = string(runes)
+		case []any:
+			if code-start >= len(v) {
+				return
+			}
+			if s, ok := v[code-start].(string); ok {
+				f.toUnicode[key] = utf16BE(s)
+			}
+		}
+	}
+}
+
+func codeValue(code string) int {
+	v := 0
+	for i := 0; i < len(code); i++ {
+		v = v<<8 | int(code[i])
+	}
+	return v
+}
+
+func codeString(v, width int) string {
+	b := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return string(b)
+}
+
+// utf16BE decodes the UTF-16BE characters of a ToUnicode map
+func utf16BE(s string) string {
+	units := make([]uint16, len(s)/2)
+	for i := range units {
+		units[i] = uint16(s[2*i])<<8 | uint16(s[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decode returns the text of a string shown with the font
+func (f *pdfFont) decode(s string) string {
+	if f == nil {
+		return latin1(s)
+	}
+	if f.toUnicode == nil {
+		if f.composite {
+			return ""
+		}
+		var b strings.Builder
+		for i := 0; i < len(s); i++ {
+			if text, ok := f.difference[s[i]]; ok {
+				b.WriteString(text)
+			} else {
+				b.WriteString(latin1(s[i : i+1]))
+			}
+		}
+		return b.String()
+	}
+	widths := f.widths
+	if len(widths) == 0 {
+		widths = []int{1}
+		if f.composite {
+			widths = []int{2}
+		}
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		matched := false
+		for _, w := range widths {
+			if i+w > len(s) {
+				continue
+			}
+			if text, ok := f.toUnicode[s[i:i+w]]; ok {
+				b.WriteString(text)
+				i += w
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i += widths[len(widths)-1]
+		}
+	}
+	return b.String()
+}
+
+// latin1 decodes the bytes of a simple font without a ToUnicode map, ignoring
+// the control characters
+func latin1(s string) string {
+	runes := make([]rune, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if r := rune(s[i]); !unicode.IsControl(r) {
+			runes = append(runes, r)
+		}
+	}
+	return string(runes)
+}
+
+// glyphs maps the glyph names of the encoding differences which are not a
+// single letter to their text
+var glyphs = map[string]string{
+	"fi": "fi", "fl": "fl", "ff": "ff", "ffi": "ffi", "ffl": "ffl",
+	"quoteright": "’", "quoteleft": "‘", "quotedblleft": "“", "quotedblright": "”",
+	"quotesingle": "'", "endash": "–", "emdash": "—", "bullet": "•", "space": " ",
+	"hyphen": "-", "period": ".", "comma": ",", "colon": ":", "semicolon": ";",
+	"exclam": "!", "question": "?", "parenleft": "(", "parenright": ")",
+}
+
+// glyphText returns the text of a glyph name
+func glyphText(name string) string {
+	if text, ok := glyphs[name]; ok {
+		return text
+	}
+	if strings.HasPrefix(name, "uni") && len(name) == 7 {
+		if v, err := strconv.ParseUint(name[3:], 16, 16); err == nil {
+			return string(rune(v))
+		}
+	}
+	if len([]rune(name)) == 1 {
+		return name
+	}
+	return ""
+}
+
+// showText runs the text operators of a content stream
+func showText(content []byte, fonts map[pdfName]*pdfFont) string {
+	var b strings.Builder
+	var font *pdfFont
+	var operands []any
+	size, leading := 1.0, 0.0
+	// y is the vertical position of the line, scale the vertical scale of the
+	// text matrix, shownY the position of the last text shown
+	y, scale := 0.0, 1.0
+	shownY, shown := 0.0, false
+	show := func(s any) {
+		text, ok := s.(string)
+		if !ok {
+			return
+		}
+		if dy := math.Abs(y - shownY); shown && dy > 0.01 {
+			// a gap wider than a line starts a paragraph
+			if dy > 1.8*size*math.Abs(scale) {
+				b.WriteString("\n\n")
+			} else {
+				b.WriteString("\n")
+			}
+		}
+		shownY, shown = y, true
+		b.WriteString(font.decode(text))
+	}
+	number := func(i int) float64 {
+		if i < len(operands) {
+			if f, ok := operands[i].(float64); ok {
+				return f
+			}
+		}
+		return 0
+	}
+
+	l := &pdfLexer{data: content}
+	for {
+		o, err := l.object()
+		if err != nil {
+			break
+		}
+		keyword, ok := o.(pdfKeyword)
+		if !ok {
+			operands = append(operands, o)
+			continue
+		}
+		switch keyword {
+		case "BT":
+			y, scale = 0, 1
+		case "Tf":
+			if len(operands) == 2 {
+				name, _ := operands[0].(pdfName)
+				font, size = fonts[name], number(1)
+			}
+		case "TL":
+			leading = number(0)
+		case "Td", "TD":
+			if keyword == "TD" {
+				leading = -number(1)
+			}
+			y += number(1) * scale
+		case "Tm":
+			if len(operands) == 6 {
+				y, scale = number(5), number(3)
+			}
+		case "T*":
+			y -= leading * scale
+		case "Tj":
+			if len(operands) > 0 {
+				show(operands[0])
+			}
+		case "'", "\"":
+			y -= leading * scale
+			if len(operands) > 0 {
+				show(operands[len(operands)-1])
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				items, _ := operands[0].([]any)
+				for _, item := range items {
+					// a large negative adjustment is a space between words
+					if f, ok := item.(float64); ok && f < -200 {
+						b.WriteString(" ")
+					}
+					show(item)
+				}
+			}
+		case "ET":
+			b.WriteString(" ")
+		case "ID":
+			l.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+	return b.String()
+}
+
+// pdfLexer reads the objects of a file or of a content stream
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+var errPDFEnd = errors.New("end of pdf data")
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+// skipSpace skips the white spaces and comments
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch c := l.data[l.pos]; {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// object reads the next object: a number, a reference, a name, a string, an
+// array, a dictionary or a keyword
+func (l *pdfLexer) object() (any, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, errPDFEnd
+	}
+	switch c := l.data[l.pos]; {
+	case c == '/':
+		l.pos++
+		return pdfName(l.name()), nil
+	case c == '(':
+		return l.literal(), nil
+	case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+		l.pos += 2
+		return l.dictionary()
+	case c == '<':
+		return l.hex(), nil
+	case c == '[':
+		l.pos++
+		var array []any
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				return nil, errPDFEnd
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				return array, nil
+			}
+			o, err := l.object()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, o)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return pdfKeyword(c), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return l.number(), nil
+	}
+	word := l.name()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return pdfKeyword(word), nil
+}
+
+// name reads a name or a keyword up to the next delimiter
+func (l *pdfLexer) name() string {
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelimiter(l.data[l.pos]) {
+		l.pos++
+	}
+	name := string(l.data[start:l.pos])
+	if strings.Contains(name, "#") {
+		var b strings.Builder
+		for i := 0; i < len(name); i++ {
+			if name[i] == '#' && i+2 < len(name) {
+				if v, err := strconv.ParseUint(name[i+1:i+3], 16, 8); err == nil {
+					b.WriteByte(byte(v))
+					i += 2
+					continue
+				}
+			}
+			b.WriteByte(name[i])
+		}
+		name = b.String()
+	}
+	if l.pos == start {
+		// a delimiter which starts no object
+		l.pos++
+	}
+	return name
+}
+
+// number reads a number, followed by a generation and R for a reference
+func (l *pdfLexer) number() any {
+	start := l.pos
+	l.pos++
+	for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+		l.pos++
+	}
+	text := string(l.data[start:l.pos])
+	v, _ := strconv.ParseFloat(text, 64)
+	if strings.ContainsAny(text, ".+-") {
+		return v
+	}
+	// look ahead for the generation and R of a reference
+	save := l.pos
+	l.skipSpace()
+	genStart := l.pos
+	for l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > genStart {
+		gen, _ := strconv.Atoi(string(l.data[genStart:l.pos]))
+		l.skipSpace()
+		if l.pos < len(l.data) && l.data[l.pos] == 'R' && (l.pos+1 == len(l.data) || isPDFSpace(l.data[l.pos+1]) || isPDFDelimiter(l.data[l.pos+1])) {
+			l.pos++
+			return pdfRef{num: int(v), gen: gen}
+		}
+	}
+	l.pos = save
+	return v
+}
+
+// literal reads a literal string with its escapes
+func (l *pdfLexer) literal() string {
+	l.pos++
+	var b bytes.Buffer
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return b.String()
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return b.String()
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					b.WriteByte(byte(v))
+				} else {
+					b.WriteByte(e)
+				}
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// hex reads a hexadecimal string
+func (l *pdfLexer) hex() string {
+	l.pos++
+	var digits []byte
+	for l.pos < len(l.data) && l.data[l.pos] != '>' {
+		if c := l.data[l.pos]; !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+		l.pos++
+	}
+	l.pos++
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		v, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		b[i] = byte(v)
+	}
+	return string(b)
+}
+
+// dictionary reads the keys and values of a dictionary up to >>
+func (l *pdfLexer) dictionary() (pdfDict, error) {
+	dict := make(pdfDict)
+	for {
+		l.skipSpace()
+		if l.pos+1 >= len(l.data) {
+			return nil, errPDFEnd
+		}
+		if l.data[l.pos] == '>' && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return dict, nil
+		}
+		key, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		value, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		if name, ok := key.(pdfName); ok {
+			dict[name] = value
+		}
+	}
+}
+
+// stream reads the data of the stream whose dictionary was just read, if one follows
+func (l *pdfLexer) stream(dict pdfDict) (*pdfStream, bool) {
+	l.skipSpace()
+	if !bytes.HasPrefix(l.data[l.pos:], []byte("stream")) {
+		return nil, false
+	}
+	start := l.pos + len("stream")
+	if start < len(l.data) && l.data[start] == '\r' {
+		start++
+	}
+	if start < len(l.data) && l.data[start] == '\n' {
+		start++
+	}
+	// the direct length is trusted when it is within the data and endstream follows it
+	if length, ok := dict["Length"].(float64); ok && length >= 0 && length <= float64(len(l.data)-start) {
+		end := start + int(length)
+		if bytes.HasPrefix(bytes.TrimLeft(l.data[end:], "\r\n "), []byte("endstream")) {
+			return &pdfStream{dict: dict, data: l.data[start:end]}, true
+		}
+	}
+	end := bytes.Index(l.data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil, false
+	}
+	data := bytes.TrimRight(l.data[start:start+end], "\r\n")
+	return &pdfStream{dict: dict, data: data}, true
+}
+
+// skipInlineImage skips the data of an inline image up to its EI operator
+func (l *pdfLexer) skipInlineImage() {
+	l.pos++
+	for l.pos+2 < len(l.data) {
+		if l.data[l.pos] == 'E' && l.data[l.pos+1] == 'I' && isPDFSpace(l.data[l.pos-1]) && (isPDFSpace(l.data[l.pos+2]) || l.pos+2 == len(l.data)) {
+			l.pos += 2
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
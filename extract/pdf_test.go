@@ -0,0 +1,137 @@
+package extract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// helper building a pdf document of the objects, the streams are given as
+// their dictionary and data
+func buildPDF(objects ...string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n")
+	for i, o := range objects {
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	buf.WriteString("trailer\n<< /Root 1 0 R >>\n%%EOF\n")
+	return buf.Bytes()
+}
+
+// helper building a stream object, compressed when flate is set
+func pdfStreamObject(dict string, data string, flate bool) string {
+	if flate {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		w.Write([]byte(data))
+		w.Close()
+		data = buf.String()
+		dict += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+// Test the PDF extractor reads the text of the pages with their fonts
+func TestPDF(t *testing.T) {
+	cmap := "/CIDInit /ProcSet findresource begin\nbegincmap\n1 begincodespacerange <0000> <FFFF> endcodespacerange\n" +
+		"2 beginbfchar <0001> <0048> <0002> <0069> endbfchar\n1 beginbfrange <0010> <0012> <0061> endbfrange\nendcmap"
+	first := "BT /F1 12 Tf 72 700 Td (First line of a para-) Tj 0 -14 Td (graph here.) Tj\n" +
+		"0 -40 Td [(Second) -250 (paragraph)] TJ ET"
+	second := "BT /F2 10 Tf 1 0 0 1 72 700 Tm ( ignored) Tj [<00010002> -300 <0010> 100 <00110012>] TJ ET\n" +
+		"BI /W 1 /H 1 ID \x00EI\x01 EI\nBT /F1 10 Tf 72 600 Td (Caf\\351 \\(bis\\)) Tj ET"
+	document := buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /Resources << /Font << /F1 5 0 R /F2 6 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 7 0 R >>",
+		"<< /Type /Page /Parent 2 0 R /Contents [8 0 R] >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Font /Subtype /Type0 /BaseFont /Custom /ToUnicode 9 0 R >>",
+		pdfStreamObject("", first, false),
+		pdfStreamObject("", second, true),
+		pdfStreamObject("", cmap, true),
+	)
+
+	e, err := New(FormatPDF, bytes.NewReader(document))
+	assert.Nil(t, err, "Expected no error while opening the document")
+	expected := []string{"First line of a paragraph here.", "Second paragraph", "Hi abc", "Café (bis)"}
+	assert.Equal(t, expected, readAll(t, e), "Extracted paragraphs are incorrect")
+}
+
+// Test the PDF extractor rejects encrypted and invalid documents
+func TestPDFErrors(t *testing.T) {
+	_, err := NewPDF(bytes.NewReader([]byte("not a pdf")), 9)
+	assert.NotNil(t, err, "Expected an error for a document which is not a pdf")
+
+	encrypted := buildPDF("<< /Type /Catalog /Pages 2 0 R >>", "<< /Filter /Standard /V 2 >>")
+	encrypted = append(encrypted, "trailer << /Root 1 0 R /Encrypt 2 0 R >>"...)
+	_, err = NewPDF(bytes.NewReader(encrypted), int64(len(encrypted)))
+	assert.NotNil(t, err, "Expected an error for an encrypted document")
+}
+
+// malformedPDFs are documents whose lengths, counts and offsets are out of range
+var malformedPDFs = [][]byte{
+	buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		"<< /Length -30 >>\nstream\nBT (Negative length) Tj ET\nendstream",
+	),
+	buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		"<< /Length 99999999999999999999 >>\nstream\nBT (Huge length) Tj ET\nendstream",
+	),
+	buildPDF(pdfStreamObject("/Type /ObjStm /N -1 /First 4", "5 0 << >>", false)),
+	buildPDF(pdfStreamObject("/Type /ObjStm /N 1 /First -40", "5 0 << >>", false)),
+	buildPDF(pdfStreamObject("/Type /ObjStm /N 999999999999 /First 4", "5 0 << >>", false)),
+	buildPDF(pdfStreamObject("/Type /ObjStm /N 1 /First 5", "5 -90 << >>", false)),
+	buildPDF(pdfStreamObject("/Type /ObjStm /N 1 /First 900", "5 0 << >>", false)),
+}
+
+// Test the PDF extractor reads malformed documents without panicking, falling back
+// to the endstream keyword for a length out of range
+func TestPDFMalformed(t *testing.T) {
+	for i, document := range malformedPDFs {
+		e, err := NewPDF(bytes.NewReader(document), int64(len(document)))
+		if err != nil {
+			continue
+		}
+		paragraphs := readAll(t, e)
+		switch i {
+		case 0:
+			assert.Equal(t, []string{"Negative length"}, paragraphs, "Expected the stream of a negative length")
+		case 1:
+			assert.Equal(t, []string{"Huge length"}, paragraphs, "Expected the stream of a length past the end")
+		}
+	}
+}
+
+// Fuzz the PDF extractor, which must return an error rather than panic on any document
+func FuzzNewPDF(f *testing.F) {
+	f.Add(buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+		pdfStreamObject("", "BT /F1 12 Tf 72 700 Td (Some text) Tj ET", true),
+		"<< /Type /Font /Subtype /Type0 /ToUnicode 6 0 R >>",
+		pdfStreamObject("", "1 beginbfrange <0010> <0012> <0061> endbfrange", false),
+	))
+	for _, document := range malformedPDFs {
+		f.Add(document)
+	}
+	f.Fuzz(func(t *testing.T, document []byte) {
+		e, err := NewPDF(bytes.NewReader(document), int64(len(document)))
+		if err != nil {
+			return
+		}
+		for i := 0; i < 10000; i++ {
+			if _, err := e.Next(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package extract
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// lines reads a document one line at a time
+type lines struct {
+	r    *bufio.Reader
+	done bool
+}
+
+func newLines(r io.Reader) lines {
+	return lines{r: bufio.NewReader(r)}
+}
+
+// line returns the next line without its line ending, and false once the
+// document has been read
+func (l *lines) line() (string, bool, error) {
+	if l.done {
+		return "", false, nil
+	}
+	line, err := l.r.ReadString('\n')
+	if err == io.EOF {
+		l.done = true
+		if line == "" {
+			return "", false, nil
+		}
+	} else if err != nil {
+		return "", false, err
+	}
+	return strings.TrimRight(line, "\r\n"), true, nil
+}
+
+// Text streams the paragraphs of a plain text document, separated by blank lines
+type Text struct {
+	paragraphs
+	lines
+}
+
+// NewText creates an extractor reading the text document from r
+func NewText(r io.Reader) *Text {
+	return &Text{lines: newLines(r)}
+}
+
+// Next returns the next paragraph of text, or io.EOF once the document has been read
+func (e *Text) Next() (string, error) {
+	for len(e.ready) == 0 {
+		line, ok, err := e.line()
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			e.flush()
+			if len(e.ready) == 0 {
+				return "", io.EOF
+			}
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			e.flush()
+			continue
+		}
+		e.write(line + "\n")
+	}
+	return e.next(), nil
+}
@@ -3,15 +3,14 @@ module github.com/joshy-joy/essay-word-counter
 go 1.23
 
 require (
-	github.com/PuerkitoBio/goquery v1.10.0
 	github.com/cenkalti/backoff/v4 v4.3.0
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/image v0.21.0
+	golang.org/x/net v0.30.0
 	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/andybalholm/cascadia v1.3.2 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.30.0 // indirect
 )
@@ -0,0 +1,235 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/extract"
+	"github.com/joshy-joy/essay-word-counter/language"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils/sentence"
+	"github.com/joshy-joy/essay-word-counter/utils/textstats"
+)
+
+// generate html pages made of paragraphs of words drawn from a fixed vocabulary
+func benchmarkPages(count, words int) []string {
+	r := rand.New(rand.NewSource(1))
+	vocabulary := make([]string, 5000)
+	for i := range vocabulary {
+		vocabulary[i] = fmt.Sprintf("word%d", i)
+	}
+
+	pages := make([]string, count)
+	for i := range pages {
+		var page strings.Builder
+		page.WriteString("<html><body><p>")
+		for j := 0; j < words; j++ {
+			page.WriteString(vocabulary[r.Intn(len(vocabulary))])
+			switch {
+			case j%150 == 149:
+				page.WriteString(".</p>\n<p>")
+			case j%15 == 14:
+				page.WriteString(". ")
+			default:
+				page.WriteString(" ")
+			}
+		}
+		page.WriteString("</p></body></html>")
+		pages[i] = page.String()
+	}
+	return pages
+}
+
+// Benchmark the word processing stage while scaling the number of tokenizer workers
+func BenchmarkRunTokenizers(b *testing.B) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	pages := benchmarkPages(256, 2000)
+
+	for _, workers := range []int{1, 2, 4, 8, 16, 32} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			cfg := config.Get()
+			cfg.Tokenizer.Count = workers
+			config.Set(cfg)
+
+			for i := 0; i < b.N; i++ {
+				jobChan := make(chan models.Document, len(pages))
+				for j, page := range pages {
+					jobChan <- htmlDocument(j, fmt.Sprintf("https://example.com/%d", j), page)
+				}
+				close(jobChan)
+				_, _ = runTokenizers(jobChan, newPages(len(pages)))
+			}
+		})
+	}
+}
+
+// Benchmark the scrappers reading the pages and the tokenizers counting them together,
+// while scaling the number of workers of both
+func BenchmarkScrapeAndCount(b *testing.B) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	pages := benchmarkPages(256, 2000)
+	urls := make([]string, len(pages))
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/%d", i)
+	}
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		i, _ := strconv.Atoi(path.Base(url))
+		return io.NopCloser(strings.NewReader(pages[i])), nil
+	}
+	defer unMockFetchEssay()
+
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			cfg := config.Get()
+			cfg.WebScrapper.Count = workers
+			cfg.Tokenizer.Count = workers
+			config.Set(cfg)
+			b.ReportAllocs()
+
+			var peak uint64
+			for i := 0; i < b.N; i++ {
+				peak += peakHeap(func() {
+					if _, _, err := countPages(context.Background(), urls); err != nil {
+						b.Fatal(err)
+					}
+				})
+			}
+			b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+		})
+	}
+}
+
+// peakHeap runs f while sampling the heap in use and returns the highest growth seen
+func peakHeap(f func()) uint64 {
+	var m runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&m)
+	base, peak := m.HeapInuse, m.HeapInuse
+
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				var s runtime.MemStats
+				runtime.ReadMemStats(&s)
+				if s.HeapInuse > peak {
+					peak = s.HeapInuse
+				}
+			}
+		}
+	}()
+	f()
+	close(done)
+	<-sampled
+	return peak - base
+}
+
+// Benchmark the memory used to process a single large page by streaming it
+// paragraph by paragraph, compare with BenchmarkLargePageMaterialized
+func BenchmarkLargePageStreaming(b *testing.B) {
+	_ = config.InitConfig(devConfigFilePath)
+	page := benchmarkPages(1, 500000)[0]
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak += peakHeap(func() {
+			if _, err := processDocument(context.Background(), htmlDocument(0, "https://example.com", page)); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+	b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+}
+
+// Benchmark the memory used to process a single large page when its whole text
+// is first extracted into a string, as the scrapper used to do
+func BenchmarkLargePageMaterialized(b *testing.B) {
+	_ = config.InitConfig(devConfigFilePath)
+	page := benchmarkPages(1, 500000)[0]
+	b.SetBytes(int64(len(page)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	var peak uint64
+	for i := 0; i < b.N; i++ {
+		peak += peakHeap(func() {
+			body, _ := io.ReadAll(strings.NewReader(page))
+			extractor := extract.NewHTML(strings.NewReader(string(body)))
+			var content strings.Builder
+			for {
+				paragraph, err := extractor.Next()
+				if err != nil {
+					break
+				}
+				content.WriteString(paragraph + sentence.ParagraphBreak)
+			}
+			text := strings.ToLower(content.String())
+			p := language.For(detectLanguage(text))
+			docStats := textstats.NewCounter()
+			words := make(map[string]int)
+			for _, paragraph := range sentence.Paragraphs(text) {
+				sentences := getSentences(paragraph, p)
+				docStats.AddParagraph(sentences)
+				for _, s := range sentences {
+					for _, word := range s {
+						words[word]++
+					}
+				}
+			}
+			runtime.KeepAlive(body)
+		})
+	}
+	b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+}
+
+// Benchmark the memory used to count a large vocabulary exactly and approximately
+func BenchmarkCounterVocabulary(b *testing.B) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	words := make([]string, 1000000)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%d", i)
+	}
+
+	for _, algorithm := range []string{"exact", "spaceSaving", "countMinSketch"} {
+		b.Run(algorithm, func(b *testing.B) {
+			cfg := config.Get()
+			cfg.Approximate.Enabled = algorithm != "exact"
+			cfg.Approximate.Algorithm = algorithm
+			config.Set(cfg)
+
+			var peak uint64
+			for i := 0; i < b.N; i++ {
+				peak += peakHeap(func() {
+					freq := newFrequencies()
+					for _, word := range words {
+						freq.add(word, 1)
+					}
+					runtime.KeepAlive(freq)
+				})
+			}
+			b.ReportMetric(float64(peak)/float64(b.N), "peak-heap-B/op")
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// errApproximateCheckpoint is returned when checkpointing approximate counts
+var errApproximateCheckpoint = errors.New("counts cannot be checkpointed in approximate mode")
+
+// state is the progress of a count saved to the checkpoint file: the statistics
+// of the urls counted and the frequency tables of their pages. The urls which
+// failed are not saved, so that they are fetched again when resuming.
+type state struct {
+	URLs []string `json:"urls"`
+	// Settings are the settings the words were counted with
+	Settings  string                    `json:"settings"`
+	Pages     []statePage               `json:"pages"`
+	Words     map[string]int            `json:"words"`
+	Languages map[string]map[string]int `json:"languages"`
+	Documents map[string]int            `json:"documents"`
+	// Occurrences are the word counts of every page by index, when the vocabulary is exported
+	Occurrences map[int]map[string]int `json:"occurrences,omitempty"`
+}
+
+// statePage is a url counted
+type statePage struct {
+	Index   int                  `json:"index"`
+	Stats   models.DocumentStats `json:"stats"`
+	Preview string               `json:"preview,omitempty"`
+}
+
+// countSettings returns the settings of the configuration the counts depend on, a
+// count can only be resumed with the same ones
+func countSettings() string {
+	cfg := config.Get()
+	settings, _ := json.Marshal(map[string]any{
+		"language":      cfg.Language,
+		"tokens":        cfg.Tokens,
+		"wordMinLength": cfg.WordMinLength,
+		"vocabulary":    cfg.ExportPath != constants.Empty,
+	})
+	return string(settings)
+}
+
+// countCheckpointed counts the pages of the urls like countPages, saving the progress
+// to the checkpoint file periodically and once done. When resuming, the urls counted in
+// the checkpoint are not scraped again and the counts start from its tables.
+func countCheckpointed(ctx context.Context, urls []string) (*wordCounter, *pages, error) {
+	cfg := config.Get().Checkpoint
+	if cfg.Path == constants.Empty {
+		return countPages(ctx, urls)
+	}
+
+	pages := newPages(len(urls))
+	pages.urls = urls
+	resumed := newWordCounter()
+	if cfg.Resume {
+		s, err := loadState(cfg.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := s.restore(pages, resumed); err != nil {
+			return nil, nil, fmt.Errorf("cannot resume from %s: %w", cfg.Path, err)
+		}
+		log.Printf("Resuming from %s, %d of %d urls counted", cfg.Path, len(s.Pages), len(urls))
+	}
+
+	counters := newCounters()
+	all := append([]*wordCounter{resumed}, counters...)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(time.Duration(cfg.Interval) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := save(cfg.Path, pages, all); err != nil {
+					log.Printf("Failed to save the checkpoint %s: %v", cfg.Path, err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	scrapeAndCount(ctx, pages, counters)
+	close(stop)
+	<-stopped
+	if err := save(cfg.Path, pages, all); err != nil {
+		return nil, nil, err
+	}
+	counter, err := mergeCounters(all)
+	return counter, pages, err
+}
+
+// save saves the snapshot of the counts to the checkpoint file at path
+func save(path string, pages *pages, counters []*wordCounter) error {
+	s, err := snapshot(pages, counters)
+	if err != nil {
+		return err
+	}
+	return saveState(path, s)
+}
+
+// snapshot returns the state of the urls counted, stopping the tokenizers meanwhile.
+// Approximate counts cannot be checkpointed.
+func snapshot(pages *pages, counters []*wordCounter) (state, error) {
+	pages.mu.Lock()
+	defer pages.mu.Unlock()
+	s := state{
+		URLs:      pages.urls,
+		Settings:  countSettings(),
+		Words:     make(map[string]int),
+		Languages: make(map[string]map[string]int),
+		Documents: make(map[string]int),
+	}
+	for i, stats := range pages.stats {
+		if stats.URL == constants.Empty {
+			continue
+		}
+		s.Pages = append(s.Pages, statePage{Index: i, Stats: stats, Preview: pages.previews[i]})
+	}
+	for _, c := range counters {
+		words, ok := c.words.(exactFrequencies)
+		if !ok {
+			return state{}, errApproximateCheckpoint
+		}
+		for word, count := range words {
+			s.Words[word] += count
+		}
+		for lang, freq := range c.languages {
+			words, ok := s.Languages[lang]
+			if !ok {
+				words = make(map[string]int)
+				s.Languages[lang] = words
+			}
+			exact, ok := freq.(exactFrequencies)
+			if !ok {
+				return state{}, errApproximateCheckpoint
+			}
+			for word, count := range exact {
+				words[word] += count
+			}
+		}
+		for lang, count := range c.documents {
+			s.Documents[lang] += count
+		}
+		for index, words := range c.occurrences {
+			if s.Occurrences == nil {
+				s.Occurrences = make(map[int]map[string]int)
+			}
+			// the counts of a page are never changed once it is counted
+			s.Occurrences[index] = words
+		}
+	}
+	return s, nil
+}
+
+// restore marks the urls of the state counted in pages and adds its counts to counter
+func (s state) restore(pages *pages, counter *wordCounter) error {
+	if !slices.Equal(s.URLs, pages.urls) {
+		return errors.New("the checkpoint is of other urls")
+	}
+	if s.Settings != countSettings() {
+		return errors.New("the checkpoint was counted with other language, token or export settings")
+	}
+	for _, page := range s.Pages {
+		if page.Index < 0 || page.Index >= len(pages.urls) {
+			return fmt.Errorf("the checkpoint has no url %d", page.Index)
+		}
+		pages.stats[page.Index] = page.Stats
+		pages.previews[page.Index] = page.Preview
+	}
+	counter.words = exactFrequencies(s.Words)
+	for lang, words := range s.Languages {
+		counter.languages[lang] = exactFrequencies(words)
+	}
+	for lang, count := range s.Documents {
+		counter.documents[lang] = count
+	}
+	if counter.occurrences != nil {
+		for index, words := range s.Occurrences {
+			counter.occurrences[index] = words
+		}
+	}
+	return nil
+}
+
+func loadState(path string) (state, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state{}, err
+	}
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return state{}, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if s.Words == nil {
+		s.Words = make(map[string]int)
+	}
+	return s, nil
+}
+
+// saveState writes the state to a temporary file first, so that the checkpoint is
+// never partially written
+func saveState(path string, s state) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".checkpoint-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var checkpointURLs = []string{"https://example.com/essay-1", "https://example.com/essay-2", "https://example.com/essay-3"}
+
+// interruptCount runs a checkpointed count of checkpointURLs which is stopped while the
+// last url waits for its response, the url failed failing before, and returns the
+// urls it left pending
+func interruptCount(t *testing.T, failed string) []string {
+	cfg := config.Get()
+	// the urls are requested in order
+	cfg.WebScrapper.Count = 1
+	config.Set(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requested := make(chan struct{})
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		switch url {
+		case checkpointURLs[2]:
+			close(requested)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case failed:
+			return nil, backoff.Permanent(errors.New("connection reset"))
+		}
+		return os.Open(path.Join("testdata", "pages", path.Base(url)+".html"))
+	}
+	go func() {
+		<-requested
+		cancel()
+	}()
+	counter, pages, err := countCheckpointed(ctx, checkpointURLs)
+	assert.Nil(t, err, "Expected no error counting the pages")
+	return newResult(counter, pages).Pending
+}
+
+// Test a count resumed from the checkpoint of an interrupted run only scrapes the
+// urls left and gives the result of an uninterrupted run
+func TestCountCheckpointedResume(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	cfg := config.Get()
+	cfg.ExportPath = "words.db"
+	config.Set(cfg)
+	ctx := context.Background()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	uninterrupted, uninterruptedPages, err := countPages(ctx, checkpointURLs)
+	assert.Nil(t, err, "Expected no error counting the pages")
+
+	cfg.Checkpoint.Path = statePath
+	config.Set(cfg)
+	assert.Equal(t, []string{checkpointURLs[2]}, interruptCount(t, ""), "Expected the last url to be left pending")
+
+	var mu sync.Mutex
+	var fetched []string
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		mu.Lock()
+		fetched = append(fetched, url)
+		mu.Unlock()
+		return os.Open(path.Join("testdata", "pages", path.Base(url)+".html"))
+	}
+	cfg = config.Get()
+	cfg.Checkpoint.Resume = true
+	config.Set(cfg)
+	counter, resumedPages, err := countCheckpointed(ctx, checkpointURLs)
+	assert.Nil(t, err, "Expected no error resuming the count")
+	assert.Equal(t, []string{checkpointURLs[2]}, fetched, "Expected only the url left to be scraped")
+	assert.Equal(t, newResult(uninterrupted, uninterruptedPages), newResult(counter, resumedPages), "Expected the result of an uninterrupted run")
+	expected, _ := vocabulary(uninterrupted, uninterruptedPages.stats)
+	resumed, _ := vocabulary(counter, resumedPages.stats)
+	assert.Equal(t, expected, resumed, "Expected the vocabulary of an uninterrupted run")
+
+	// the checkpoint of the complete run has every url done
+	fetched = nil
+	_, _, err = countCheckpointed(ctx, checkpointURLs)
+	assert.Nil(t, err, "Expected no error resuming a complete count")
+	assert.Empty(t, fetched, "Expected no url to be scraped again")
+
+	_, _, err = countCheckpointed(ctx, checkpointURLs[:2])
+	assert.NotNil(t, err, "Expected an error resuming the count of other urls")
+	cfg.WordMinLength++
+	config.Set(cfg)
+	_, _, err = countCheckpointed(ctx, checkpointURLs)
+	assert.NotNil(t, err, "Expected an error resuming a count with other settings")
+}
+
+// Test the urls which failed are not saved to the checkpoint, so that a resumed
+// count fetches them again
+func TestCountCheckpointedRetriesFailures(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.Checkpoint.Path = filepath.Join(t.TempDir(), "state.json")
+	config.Set(cfg)
+	defer unMockFetchEssay()
+
+	// the second url failed before the run was interrupted
+	assert.Equal(t, []string{checkpointURLs[2]}, interruptCount(t, checkpointURLs[1]), "Expected the last url to be left pending")
+
+	var fetched []string
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		fetched = append(fetched, url)
+		return os.Open(path.Join("testdata", "pages", path.Base(url)+".html"))
+	}
+	cfg = config.Get()
+	cfg.Checkpoint.Resume = true
+	config.Set(cfg)
+	counter, resumedPages, err := countCheckpointed(context.Background(), checkpointURLs)
+	assert.Nil(t, err, "Expected no error resuming the count")
+	assert.Equal(t, checkpointURLs[1:], fetched, "Expected the failed and the pending urls to be fetched again")
+	result := newResult(counter, resumedPages)
+	assert.Len(t, result.Documents, 3, "Expected every url to be counted")
+	assert.Empty(t, result.Failures, "Expected no failure left")
+}
@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/joshy-joy/essay-word-counter/compare"
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/export"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/output"
+	"github.com/joshy-joy/essay-word-counter/sources"
+)
+
+// Compare compares the words of two corpora and writes the comparison to the
+// output. Each corpus is either a vocabulary export, or a list of urls whose
+// pages are counted.
+func Compare(ctx context.Context, before, after string) error {
+	// an export which cannot be read back fails before the other corpus is counted
+	for _, path := range []string{before, after} {
+		if err := export.CheckReadable(path); err != nil {
+			return err
+		}
+	}
+	// open the output file first so that a wrong path fails before scraping
+	w, file, err := createOutput()
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		defer file.Close()
+	}
+
+	wordsBefore, err := loadWords(ctx, before)
+	if err != nil {
+		return err
+	}
+	wordsAfter, err := loadWords(ctx, after)
+	if err != nil {
+		return err
+	}
+	c := compare.Compare(wordsBefore, wordsAfter, config.Get().ResultLength)
+	if err := output.WriteComparison(w, config.Get().OutputFormat, c); err != nil {
+		return err
+	}
+	if file != nil {
+		return file.Close()
+	}
+	return nil
+}
+
+// loadWords returns every word of a corpus ranked by count, read from an export
+// when path has the extension of one, counted from the pages of the urls of the list otherwise
+func loadWords(ctx context.Context, path string) ([]models.WordCount, error) {
+	if _, err := export.Format(path); err == nil {
+		return export.ReadWords(path)
+	}
+	list, err := sourcesRead(ctx, sources.Input{Lists: []string{path}})
+	if err != nil {
+		return nil, err
+	}
+	counter, _, err := countPages(ctx, list.URLs)
+	if err != nil {
+		return nil, err
+	}
+	// approximate counts miss the words of the comparison
+	if counter.words.approximation() != nil {
+		return nil, errors.New("corpora cannot be compared in approximate mode")
+	}
+	return counter.words.top(0), nil
+}
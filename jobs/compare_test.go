@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the pages of a url list are compared with a vocabulary export
+func TestCompare(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	dir := t.TempDir()
+	before := path.Join(dir, "before.csv")
+	assert.Nil(t, os.WriteFile(before, []byte("id,word,count,documents\n1,the,9,1\n2,gone,5,1\n3,les,2,1\n4,screens,1,1\n"), 0644), "Expected no error writing the export")
+	cfg := config.Get()
+	cfg.OutputFormat = "csv"
+	cfg.ResultLength = 1
+	config.Set(cfg)
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.Nil(t, Compare(context.Background(), before, "testdata/urls.txt"), "Expected no error from Compare")
+	assert.Contains(t, out.String(), "\nrising,les,3,2,2,6,", "Expected les to rise")
+	assert.Contains(t, out.String(), "\ndisappeared,gone,2,0,5,0,", "Expected gone to disappear")
+
+	// the urls are not counted when the other corpus cannot be read
+	fetched := false
+	externalsFetchEssay = func(_ context.Context, _, _ string) (io.ReadCloser, error) {
+		fetched = true
+		return nil, errors.New("not fetched")
+	}
+	err := Compare(context.Background(), "testdata/urls.txt", path.Join(dir, "after.db"))
+	assert.EqualError(t, err, "cannot read the words of "+path.Join(dir, "after.db")+", only CSV exports can be read back", "Expected an error for a SQLite export")
+	assert.NotNil(t, Compare(context.Background(), path.Join(dir, "before.parquet"), "testdata/urls.txt"), "Expected an error for a Parquet export")
+	assert.False(t, fetched, "Expected no page to be fetched")
+}
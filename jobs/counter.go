@@ -0,0 +1,290 @@
+package jobs
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils/sketch"
+	"github.com/joshy-joy/essay-word-counter/utils/topk"
+)
+
+// frequencies counts how many times every word appears
+type frequencies interface {
+	add(word string, count int)
+	// merge adds the counts of other, which must have the same concrete type
+	merge(other frequencies) error
+	count(word string) int
+	top(n int) []models.WordCount
+	// approximation returns the error bounds of the counts, nil when they are exact
+	approximation() *models.Approximation
+}
+
+// newFrequencies creates exact counts, or approximate ones bounded in memory
+// when approximate mode is enabled
+func newFrequencies() frequencies {
+	approximate := config.Get().Approximate
+	if !approximate.Enabled {
+		return exactFrequencies{}
+	}
+	capacity := sketch.Capacity(approximate.Epsilon)
+	if approximate.Algorithm == sketch.CountMinSketchAlgorithm {
+		return &heavyHitters{sketch.NewHeavyHitters(approximate.Epsilon, approximate.Delta, capacity)}
+	}
+	return &spaceSaving{sketch.NewSpaceSaving(capacity)}
+}
+
+var (
+	// errMixedCounts is returned when merging counts of different kinds
+	errMixedCounts = errors.New("exact and approximate counts cannot be merged")
+	// errApproximateExport is returned when exporting approximate counts
+	errApproximateExport = errors.New("the complete vocabulary cannot be exported in approximate mode")
+)
+
+// exactFrequencies keeps every word in a map
+type exactFrequencies map[string]int
+
+func (f exactFrequencies) add(word string, count int) { f[word] += count }
+func (f exactFrequencies) merge(other frequencies) error {
+	o, ok := other.(exactFrequencies)
+	if !ok {
+		return errMixedCounts
+	}
+	for word, count := range o {
+		f[word] += count
+	}
+	return nil
+}
+func (f exactFrequencies) count(word string) int                { return f[word] }
+func (f exactFrequencies) top(n int) []models.WordCount         { return topWords(f, n) }
+func (f exactFrequencies) approximation() *models.Approximation { return nil }
+
+// spaceSaving keeps the most frequent words in a Space-Saving summary
+type spaceSaving struct{ *sketch.SpaceSaving }
+
+func (f *spaceSaving) add(word string, count int) { f.Add(word, count) }
+func (f *spaceSaving) merge(other frequencies) error {
+	o, ok := other.(*spaceSaving)
+	if !ok {
+		return errMixedCounts
+	}
+	f.Merge(o.SpaceSaving)
+	return nil
+}
+func (f *spaceSaving) count(word string) int        { return f.Estimate(word) }
+func (f *spaceSaving) top(n int) []models.WordCount { return wordCounts(f.Top(n)) }
+func (f *spaceSaving) approximation() *models.Approximation {
+	return newApproximation(f.Total(), f.Counters())
+}
+
+// heavyHitters keeps the most frequent words estimated by a Count-Min Sketch
+type heavyHitters struct{ *sketch.HeavyHitters }
+
+func (f *heavyHitters) add(word string, count int) { f.Add(word, count) }
+func (f *heavyHitters) merge(other frequencies) error {
+	o, ok := other.(*heavyHitters)
+	if !ok {
+		return errMixedCounts
+	}
+	// the sketches only merge when created with the same dimensions
+	return f.Merge(o.HeavyHitters)
+}
+func (f *heavyHitters) count(word string) int        { return f.Estimate(word) }
+func (f *heavyHitters) top(n int) []models.WordCount { return wordCounts(f.Top(n)) }
+func (f *heavyHitters) approximation() *models.Approximation {
+	return newApproximation(f.Total(), f.Counters())
+}
+
+// newApproximation reports the configured error bounds for the number of words counted
+func newApproximation(words, counters int) *models.Approximation {
+	approximate := config.Get().Approximate
+	result := &models.Approximation{
+		Algorithm: approximate.Algorithm,
+		Epsilon:   approximate.Epsilon,
+		Words:     words,
+		MaxError:  int(approximate.Epsilon * float64(words)),
+		Counters:  counters,
+	}
+	if approximate.Algorithm == sketch.CountMinSketchAlgorithm {
+		result.Delta = approximate.Delta
+	}
+	return result
+}
+
+// wordCounter holds the word frequencies counted by a single tokenizer worker,
+// so that the workers never share a lock while counting
+type wordCounter struct {
+	words frequencies
+	// per language word frequencies and number of documents
+	languages map[string]frequencies
+	documents map[string]int
+	// occurrences keeps the word counts of every document by index when the
+	// vocabulary is exported, nil otherwise
+	occurrences map[int]map[string]int
+}
+
+func newWordCounter() *wordCounter {
+	c := &wordCounter{
+		words:     newFrequencies(),
+		languages: make(map[string]frequencies),
+		documents: make(map[string]int),
+	}
+	if config.Get().ExportPath != constants.Empty {
+		c.occurrences = make(map[int]map[string]int)
+	}
+	return c
+}
+
+// addDocument adds the word counts of the document at index, written in lang
+func (c *wordCounter) addDocument(index int, lang string, words map[string]int) {
+	if c.occurrences != nil {
+		c.occurrences[index] = words
+	}
+	c.documents[lang]++
+	freq, ok := c.languages[lang]
+	if !ok {
+		freq = newFrequencies()
+		c.languages[lang] = freq
+	}
+	for word, count := range words {
+		c.words.add(word, count)
+		freq.add(word, count)
+	}
+}
+
+// merge adds the counts of other to the counter
+func (c *wordCounter) merge(other *wordCounter) error {
+	if err := c.words.merge(other.words); err != nil {
+		return err
+	}
+	for lang, freq := range other.languages {
+		merged, ok := c.languages[lang]
+		if !ok {
+			c.languages[lang] = freq
+			continue
+		}
+		if err := merged.merge(freq); err != nil {
+			return err
+		}
+	}
+	for lang, count := range other.documents {
+		c.documents[lang] += count
+	}
+	for index, words := range other.occurrences {
+		c.occurrences[index] = words
+	}
+	return nil
+}
+
+// runTokenizers starts the word processing workers on jobChan and merges their
+// counts once every document has been processed
+func runTokenizers(jobChan chan models.Document, pages *pages) (*wordCounter, error) {
+	counters := newCounters()
+	tokenize(jobChan, pages, counters)
+	return mergeCounters(counters)
+}
+
+// newCounters creates the counters of the word processing workers
+func newCounters() []*wordCounter {
+	counters := make([]*wordCounter, config.Get().Tokenizer.Count)
+	for i := range counters {
+		counters[i] = newWordCounter()
+	}
+	return counters
+}
+
+// tokenize runs a word processing worker per counter on jobChan until it is closed
+func tokenize(jobChan chan models.Document, pages *pages, counters []*wordCounter) {
+	var wg sync.WaitGroup
+	for _, counter := range counters {
+		wg.Add(1)
+		go tokenizer(jobChan, &wg, counter, pages)
+	}
+	wg.Wait()
+}
+
+// mergeCounters adds the counts of the counters together
+func mergeCounters(counters []*wordCounter) (*wordCounter, error) {
+	total := newWordCounter()
+	for _, c := range counters {
+		if err := total.merge(c); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+// topWords keeps the n most frequent words, in descending order of count
+func topWords(freq map[string]int, n int) []models.WordCount {
+	tracker := topk.NewOrdered[string, int](n)
+	for word, count := range freq {
+		tracker.Set(word, count)
+	}
+	return wordCounts(tracker.Top(n))
+}
+
+// wordCounts ranks the words of items, which are in descending order
+func wordCounts(items []topk.Item[string, int]) []models.WordCount {
+	result := make([]models.WordCount, len(items))
+	for i, item := range items {
+		result[i] = models.WordCount{Rank: i + 1, Word: item.Key, Count: item.Score}
+	}
+	return result
+}
+
+// languageResults computes the top words of every language, sorted by language
+func languageResults(c *wordCounter) []models.LanguageResult {
+	languages := make([]models.LanguageResult, 0, len(c.documents))
+	for lang, documents := range c.documents {
+		freq := c.languages[lang]
+		languages = append(languages, models.LanguageResult{
+			Language:      lang,
+			Documents:     documents,
+			TopWords:      freq.top(config.Get().ResultLength),
+			Approximation: freq.approximation(),
+		})
+	}
+	sort.Slice(languages, func(i, j int) bool { return languages[i].Language < languages[j].Language })
+	return languages
+}
+
+// vocabulary builds the complete frequency table of an exact counter. Words are
+// numbered from the most to the least frequent and documents by their position
+// in the input list, starting at 1. Approximate counts cannot be exported.
+func vocabulary(c *wordCounter, stats []models.DocumentStats) (models.Vocabulary, error) {
+	words, ok := c.words.(exactFrequencies)
+	if !ok {
+		return models.Vocabulary{}, errApproximateExport
+	}
+	documentFrequency := make(map[string]int)
+	for _, words := range c.occurrences {
+		for word := range words {
+			documentFrequency[word]++
+		}
+	}
+
+	var v models.Vocabulary
+	ids := make(map[string]int)
+	for _, w := range topWords(words, 0) {
+		ids[w.Word] = w.Rank
+		v.Words = append(v.Words, models.VocabularyWord{ID: w.Rank, Word: w.Word, Count: w.Count, Documents: documentFrequency[w.Word]})
+	}
+
+	for index, s := range stats {
+		if s.URL == constants.Empty {
+			continue
+		}
+		v.Documents = append(v.Documents, models.VocabularyDocument{ID: index + 1, URL: s.URL, Language: s.Language, Words: s.Words, UniqueWords: s.UniqueWords})
+		words := c.occurrences[index]
+		occurrences := make([]models.Occurrence, 0, len(words))
+		for word, count := range words {
+			occurrences = append(occurrences, models.Occurrence{DocumentID: index + 1, WordID: ids[word], Count: count})
+		}
+		sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].WordID < occurrences[j].WordID })
+		v.Occurrences = append(v.Occurrences, occurrences...)
+	}
+	return v, nil
+}
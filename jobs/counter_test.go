@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vocabulary numbers the documents by their position in the input list
+func TestVocabulary(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.ExportPath = "words.db"
+	config.Set(cfg)
+
+	counter := newWordCounter()
+	counter.addDocument(0, "en", map[string]int{"essay": 2, "word": 1})
+	counter.addDocument(2, "en", map[string]int{"essay": 1})
+	stats := []models.DocumentStats{{URL: "https://example.com/1"}, {}, {URL: "https://example.com/3"}}
+
+	v, err := vocabulary(counter, stats)
+	assert.Nil(t, err, "Expected no error building the vocabulary")
+	assert.Equal(t, []models.VocabularyWord{{ID: 1, Word: "essay", Count: 3, Documents: 2}, {ID: 2, Word: "word", Count: 1, Documents: 1}}, v.Words, "Vocabulary words mismatch")
+	assert.Equal(t, []int{1, 3}, []int{v.Documents[0].ID, v.Documents[1].ID}, "Expected the failed document to be skipped")
+	expected := []models.Occurrence{{DocumentID: 1, WordID: 1, Count: 2}, {DocumentID: 1, WordID: 2, Count: 1}, {DocumentID: 3, WordID: 1, Count: 1}}
+	assert.Equal(t, expected, v.Occurrences, "Occurrences mismatch")
+}
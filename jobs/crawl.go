@@ -0,0 +1,256 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/extract"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"golang.org/x/net/html"
+)
+
+// Crawl counts the pages of the seed urls and of the pages of the same sites
+// they link to, breadth first, and writes the result like StartWorkerPool
+func Crawl(ctx context.Context) error {
+	include, err := CompilePatterns(config.Get().Crawl.Include)
+	if err != nil {
+		return err
+	}
+	exclude, err := CompilePatterns(config.Get().Crawl.Exclude)
+	if err != nil {
+		return err
+	}
+	return countAndWrite(ctx, func(ctx context.Context, seeds []string) (*wordCounter, *pages, error) {
+		return crawlPages(ctx, newCrawler(seeds, include, exclude))
+	})
+}
+
+// crawlLink is a page to crawl, found depth links away from a seed
+type crawlLink struct {
+	index int
+	url   string
+	depth int
+}
+
+// crawler discovers the pages to count. The frontier can hold every page to
+// crawl, so that queuing a link never blocks.
+type crawler struct {
+	seeds            []string
+	include, exclude []*regexp.Regexp
+	maxDepth         int
+	// sites are the hosts of the seeds, the only ones whose links are followed
+	sites map[string]bool
+
+	mu      sync.Mutex
+	visited map[string]bool
+	pages   *pages
+	// pending counts the pages queued and not crawled yet
+	pending  sync.WaitGroup
+	frontier chan crawlLink
+	// hosts limits the pages fetched at once from every host
+	hosts   map[string]chan struct{}
+	perHost int
+}
+
+// CompilePatterns compiles the include or exclude patterns of the crawler
+func CompilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = re
+	}
+	return compiled, nil
+}
+
+func newCrawler(seeds []string, include, exclude []*regexp.Regexp) *crawler {
+	cfg := config.Get().Crawl
+	c := &crawler{
+		seeds:    seeds,
+		include:  include,
+		exclude:  exclude,
+		maxDepth: cfg.MaxDepth,
+		sites:    make(map[string]bool),
+		visited:  make(map[string]bool),
+		pages:    newPages(cfg.MaxPages),
+		frontier: make(chan crawlLink, cfg.MaxPages),
+		hosts:    make(map[string]chan struct{}),
+		perHost:  cfg.PerHost,
+	}
+	c.pages.urls = make([]string, 0, cfg.MaxPages)
+	for _, seed := range seeds {
+		if u, err := url.Parse(seed); err == nil {
+			c.sites[strings.ToLower(u.Host)] = true
+		}
+	}
+	return c
+}
+
+// crawlPages crawls from the seed urls and counts the words of the pages found
+func crawlPages(ctx context.Context, c *crawler) (*wordCounter, *pages, error) {
+	for _, seed := range c.seeds {
+		c.queue(seed, 0)
+	}
+	go func() {
+		c.pending.Wait()
+		close(c.frontier)
+	}()
+
+	jobChan := make(chan models.Document, config.Get().Tokenizer.Count)
+	var wg sync.WaitGroup
+	for i := 0; i < config.Get().WebScrapper.Count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range c.frontier {
+				c.crawl(ctx, link, jobChan)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(jobChan)
+	}()
+
+	stop := reportProgress(c.pages.progress)
+	counter, err := runTokenizers(jobChan, c.pages)
+	stop()
+	// only the pages queued were crawled
+	n := len(c.pages.urls)
+	c.pages.stats, c.pages.failures, c.pages.previews = c.pages.stats[:n], c.pages.failures[:n], c.pages.previews[:n]
+	return counter, c.pages, err
+}
+
+// queue adds the url to the frontier, unless it was already queued or enough
+// pages were
+func (c *crawler) queue(link string, depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.visited[link] || len(c.pages.urls) == cap(c.pages.urls) {
+		return
+	}
+	c.visited[link] = true
+	c.pending.Add(1)
+	c.frontier <- crawlLink{index: len(c.pages.urls), url: link, depth: depth}
+	c.pages.progress.total.Add(1)
+	c.pages.urls = append(c.pages.urls, link)
+}
+
+// host returns the semaphore of the host of the url
+func (c *crawler) host(link string) chan struct{} {
+	u, _ := url.Parse(link)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sem, ok := c.hosts[u.Host]
+	if !ok {
+		sem = make(chan struct{}, c.perHost)
+		c.hosts[u.Host] = sem
+	}
+	return sem
+}
+
+// crawl fetches the page, queues the links it follows and sends the page to the tokenizers
+func (c *crawler) crawl(ctx context.Context, link crawlLink, jobChan chan models.Document) {
+	defer c.pending.Done()
+	// once the run is stopped the pages left are not crawled
+	if ctx.Err() != nil {
+		return
+	}
+
+	page, format, err := readDocument(ctx, link.url, c.host(link.url), &c.pages.progress.bytes)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Failed to crawl %s after retries: %v", link.url, err)
+		c.pages.fail(link.index, err.Error())
+		return
+	}
+
+	if link.depth < c.maxDepth && format == extract.FormatHTML {
+		base, _ := url.Parse(link.url)
+		for _, l := range links(bytes.NewReader(page), base) {
+			if c.follows(l) {
+				c.queue(l, link.depth+1)
+			}
+		}
+	}
+	jobChan <- models.Document{Index: link.index, URL: link.url, Format: format, Body: io.NopCloser(bytes.NewReader(page))}
+}
+
+// follows reports whether the link is on the site of a seed and matches the patterns
+func (c *crawler) follows(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil || !c.sites[strings.ToLower(u.Host)] {
+		return false
+	}
+	included := len(c.include) == 0
+	for _, re := range c.include {
+		included = included || re.MatchString(link)
+	}
+	for _, re := range c.exclude {
+		if re.MatchString(link) {
+			return false
+		}
+	}
+	return included
+}
+
+// links returns the http links of an html page, resolved against its base url,
+// without their fragment
+func links(r io.Reader, base *url.URL) []string {
+	var found []string
+	tokenizer := html.NewTokenizer(r)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return found
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := tokenizer.TagName()
+			tag := string(name)
+			if (tag != "a" && tag != "base") || !hasAttr {
+				continue
+			}
+			href := attribute(tokenizer, "href")
+			if href == constants.Empty {
+				continue
+			}
+			u, err := base.Parse(strings.TrimSpace(href))
+			if err != nil {
+				continue
+			}
+			if tag == "base" {
+				base = u
+				continue
+			}
+			if u.Scheme != "http" && u.Scheme != "https" {
+				continue
+			}
+			u.Fragment, u.RawFragment = constants.Empty, constants.Empty
+			found = append(found, u.String())
+		}
+	}
+}
+
+// attribute returns the value of the attribute of the current tag
+func attribute(tokenizer *html.Tokenizer, name string) string {
+	for {
+		key, value, more := tokenizer.TagAttr()
+		if string(key) == name {
+			return string(value)
+		}
+		if !more {
+			return constants.Empty
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// site is the pages of the crawled site by url
+var site = map[string]string{
+	"https://example.com/":   `<a href="/a">A</a> <a href="b#comments">B</a> <a href="/private/c">C</a> <a href="https://other.com/d">D</a> <a href="mailto:me@example.com">Me</a> <a href="/missing">Missing</a><p>home page</p>`,
+	"https://example.com/a":  `<a href="/a2">A2</a> <a href="/">Home</a><p>essay about sony</p>`,
+	"https://example.com/b":  `<a href="/b2">B2</a><p>essay about yamaha</p>`,
+	"https://example.com/a2": `<a href="/a3">A3</a><p>second essay about sony</p>`,
+	"https://example.com/b2": `<p>second essay about yamaha</p>`,
+	"https://example.com/a3": `<p>third essay about sony</p>`,
+}
+
+// mockFetchSite serves the pages of the site, recording the most pages fetched at once
+func mockFetchSite() *int {
+	var mu sync.Mutex
+	fetching, most := 0, 0
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		mu.Lock()
+		fetching++
+		most = max(most, fetching)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			fetching--
+			mu.Unlock()
+		}()
+		page, ok := site[url]
+		if !ok {
+			return nil, errors.New("non-200 status code 404")
+		}
+		return io.NopCloser(strings.NewReader("<html><body>" + page + "</body></html>")), nil
+	}
+	return &most
+}
+
+// crawlSite crawls the site from its home page with the patterns
+func crawlSite(include, exclude string) ([]string, []string) {
+	c := newCrawler([]string{"https://example.com/"}, compile(include), compile(exclude))
+	_, pages, _ := crawlPages(context.Background(), c)
+	result := newResult(newWordCounter(), pages)
+	failed := make([]string, len(result.Failures))
+	for i, f := range result.Failures {
+		failed[i] = f.URL
+	}
+	return pages.urls, failed
+}
+
+func compile(pattern string) []*regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	return []*regexp.Regexp{regexp.MustCompile(pattern)}
+}
+
+// Test the crawler follows the links of the site up to the maximum depth, once each
+func TestCrawl(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.WebScrapper.Count = 4
+	cfg.Crawl.PerHost = 1
+	config.Set(cfg)
+	most := mockFetchSite()
+	defer unMockFetchEssay()
+
+	urls, failed := crawlSite("", "/private/")
+	assert.ElementsMatch(t, []string{"https://example.com/", "https://example.com/a", "https://example.com/b", "https://example.com/missing", "https://example.com/a2", "https://example.com/b2"}, urls, "Expected the pages of the site up to depth 2")
+	assert.Equal(t, []string{"https://example.com/missing"}, failed, "Expected the missing page to fail")
+	assert.Equal(t, 1, *most, "Expected a single page fetched at once from the host")
+
+	urls, _ = crawlSite(`/a\d*$`, "")
+	assert.ElementsMatch(t, []string{"https://example.com/", "https://example.com/a", "https://example.com/a2"}, urls, "Expected only the included links to be followed")
+
+	cfg.Crawl.MaxPages = 2
+	config.Set(cfg)
+	urls, _ = crawlSite("", "")
+	assert.Equal(t, []string{"https://example.com/", "https://example.com/a"}, urls, "Expected the crawl to stop at the maximum number of pages")
+}
+
+// Test links resolves the links of a page against its base
+func TestLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/2019/08/essay")
+	page := `<a href="next">Next</a><base href="https://example.com/archive/"><a href="old#top">Old</a><a>None</a><a href="javascript:void(0)">Js</a>`
+	assert.Equal(t, []string{"https://example.com/2019/08/next", "https://example.com/archive/old"}, links(strings.NewReader(page), base), "Links mismatch")
+}
@@ -0,0 +1,294 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/joshy-joy/essay-word-counter/cache"
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/extract"
+	"github.com/joshy-joy/essay-word-counter/sources"
+	"github.com/joshy-joy/essay-word-counter/utils/sentence"
+)
+
+// openPage returns the page of the url. With a cache directory, the cached page is
+// used while it is fresh, revalidated with a conditional request once it is not,
+// and the pages fetched are cached. Offline, only the cached pages are read.
+func openPage(ctx context.Context, url string) (io.ReadCloser, error) {
+	cfg := config.Get().Cache
+	if cfg.Dir == constants.Empty {
+		return externalsFetchEssay(ctx, "GET", url)
+	}
+	ttl, err := cache.ParseTTL(cfg.TTL)
+	if err != nil {
+		return nil, backoff.Permanent(err)
+	}
+	c := cache.New(cfg.Dir)
+	entry, err := c.Lookup(url)
+	cached := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	switch {
+	case cached && (cfg.Offline || entry.Fresh(ttl)):
+		return c.Body(entry)
+	case cfg.Offline:
+		// the page will not be cached on a retry either
+		return nil, backoff.Permanent(fmt.Errorf("%s is not cached", url))
+	}
+	entry, err = fetchToCache(ctx, c, url, entry)
+	if err != nil {
+		return nil, err
+	}
+	return c.Body(entry)
+}
+
+// fetchToCache fetches the page of the url to the cache, conditionally when the entry
+// of a cached copy is given, and returns the entry of the page
+func fetchToCache(ctx context.Context, c *cache.Cache, url string, entry cache.Entry) (cache.Entry, error) {
+	resp, err := externalsFetch(ctx, url, entry.ETag, entry.LastModified)
+	if err != nil {
+		return cache.Entry{}, err
+	}
+	if resp.NotModified {
+		return c.Revalidated(entry, resp.Header)
+	}
+	defer resp.Body.Close()
+	return c.Store(url, resp.Header, resp.Body)
+}
+
+// openDocument returns the document of the url and its format: the local document of
+// a file url, else the html page of openPage
+func openDocument(ctx context.Context, link string) (io.ReadCloser, string, error) {
+	path, ok := sources.FilePath(link)
+	if !ok {
+		body, err := openPage(ctx, link)
+		return body, extract.FormatHTML, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		// reading the file again would fail the same way
+		return nil, constants.Empty, backoff.Permanent(err)
+	}
+	return f, extract.FormatOf(path), nil
+}
+
+// newBackOff returns the retry policy of the requests, which stops retrying once
+// ctx is done
+func newBackOff(ctx context.Context) backoff.BackOff {
+	return backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5), ctx)
+}
+
+// maxPageSize bounds the bytes read from a document, a larger one failing the url
+var maxPageSize int64 = 64 << 20
+
+// readDocument reads the document of the url and returns it with its format. The
+// document is read in the attempts for an error while reading it to be retried, and
+// for the request to be done before the document waits for a tokenizer. The url
+// timeout bounds the attempts, which hold sem when it is not nil. Once ctx is done no
+// attempt is retried and a request waiting for its response is cancelled, a document
+// already being read being still read to the end.
+func readDocument(ctx context.Context, url string, sem chan struct{}, read *atomic.Int64) ([]byte, string, error) {
+	timeout := config.Get().Timeouts.URL
+	retryCtx, cancelRetry := withTimeout(ctx, "url timeout", timeout)
+	defer cancelRetry()
+	fetchCtx, cancelFetch := withTimeout(context.WithoutCancel(ctx), "url timeout", timeout)
+	defer cancelFetch()
+
+	var page []byte
+	var format string
+	operation := func() error {
+		if sem != nil {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+		}
+		// ctx cancels the request until its response arrives, fetchCtx only once
+		// the body is being read
+		attemptCtx, cancelAttempt := context.WithCancelCause(fetchCtx)
+		defer cancelAttempt(nil)
+		stop := context.AfterFunc(ctx, func() { cancelAttempt(context.Cause(ctx)) })
+		body, f, err := openDocument(attemptCtx, url)
+		if !stop() {
+			if err == nil {
+				body.Close()
+			}
+			return backoff.Permanent(context.Cause(ctx))
+		}
+		format = f
+		if err != nil {
+			log.Printf("error getting url response")
+			return err
+		}
+		defer body.Close()
+		page, err = io.ReadAll(io.LimitReader(countingReader{body, read}, maxPageSize+1))
+		if err == nil && int64(len(page)) > maxPageSize {
+			// the document would be as large on a retry
+			return backoff.Permanent(fmt.Errorf("the document is larger than %s", formatBytes(maxPageSize)))
+		}
+		return err
+	}
+
+	// Retry on failure with exponential backoff
+	if err := backoff.Retry(operation, newBackOff(retryCtx)); err != nil {
+		if retryCtx.Err() != nil {
+			err = context.Cause(retryCtx)
+		}
+		return nil, format, err
+	}
+	return page, format, nil
+}
+
+// forEach calls f with the index of every one of n urls from the given number of workers
+func forEach(n, workers int, f func(i int)) {
+	indexes := make(chan int, n)
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				f(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Fetch downloads the pages of the urls to the cache, revalidating the cached ones,
+// without counting them
+func Fetch(ctx context.Context) error {
+	dir := config.Get().Cache.Dir
+	if dir == constants.Empty {
+		return errors.New("no cache directory to fetch the pages to")
+	}
+	list, err := sourcesRead(ctx, sources.Configured())
+	if err != nil {
+		return err
+	}
+	urls := list.URLs
+
+	c := cache.New(dir)
+	failed := make([]bool, len(urls))
+	forEach(len(urls), config.Get().WebScrapper.Count, func(i int) {
+		// local documents are read where they are
+		if _, ok := sources.FilePath(urls[i]); ok {
+			return
+		}
+		operation := func() error {
+			// the cached pages are revalidated whatever their age
+			entry, err := c.Lookup(urls[i])
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			_, err = fetchToCache(ctx, c, urls[i], entry)
+			return err
+		}
+		if err := backoff.Retry(operation, newBackOff(ctx)); err != nil {
+			log.Printf("Failed to fetch %s after retries: %v", urls[i], err)
+			failed[i] = true
+		}
+	})
+
+	failures := 0
+	for _, f := range failed {
+		if f {
+			failures++
+		}
+	}
+	fmt.Fprintf(stdout, "fetched %d of %d pages to %s\n", len(urls)-failures, len(urls), dir)
+	if failures > 0 {
+		return fmt.Errorf("%d of %d pages could not be fetched", failures, len(urls))
+	}
+	return nil
+}
+
+// Extract writes the text extracted from the page of every url to the output,
+// each one under a line holding its url, in the order of the urls
+func Extract(ctx context.Context) error {
+	list, err := sourcesRead(ctx, sources.Configured())
+	if err != nil {
+		return err
+	}
+	urls := list.URLs
+	w, file, err := createOutput()
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		defer file.Close()
+	}
+
+	texts := make([]string, len(urls))
+	failed := make([]bool, len(urls))
+	forEach(len(urls), config.Get().WebScrapper.Count, func(i int) {
+		var text string
+		operation := func() error {
+			body, format, err := openDocument(ctx, urls[i])
+			if err != nil {
+				return err
+			}
+			defer body.Close()
+			text, err = extractText(body, format)
+			return err
+		}
+		if err := backoff.Retry(operation, newBackOff(ctx)); err != nil {
+			log.Printf("Failed to extract %s after retries: %v", urls[i], err)
+			failed[i] = true
+			return
+		}
+		texts[i] = text
+	})
+
+	failures := 0
+	for i, text := range texts {
+		if failed[i] {
+			failures++
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# %s\n\n%s\n\n", urls[i], text); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	if failures > 0 {
+		return fmt.Errorf("%d of %d pages could not be extracted", failures, len(urls))
+	}
+	return nil
+}
+
+// extractText returns the paragraphs of a document of the format separated by blank lines
+func extractText(body io.Reader, format string) (string, error) {
+	extractor, err := extract.New(format, body)
+	if err != nil {
+		return constants.Empty, backoff.Permanent(err)
+	}
+	var paragraphs []string
+	for {
+		paragraph, err := extractor.Next()
+		if err == io.EOF {
+			return strings.Join(paragraphs, sentence.ParagraphBreak), nil
+		}
+		if err != nil {
+			return constants.Empty, err
+		}
+		paragraphs = append(paragraphs, paragraph)
+	}
+}
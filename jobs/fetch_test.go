@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/externals"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the pages fetched to the cache are extracted without being fetched again
+func TestFetchExtract(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.DefaultFilePath = "testdata/urls.txt"
+	config.Set(cfg)
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.NotNil(t, Fetch(context.Background()), "Expected an error without a cache directory")
+	cfg.Cache.Dir = t.TempDir()
+	config.Set(cfg)
+	assert.Nil(t, Fetch(context.Background()), "Expected no error from Fetch")
+	assert.Equal(t, "fetched 3 of 3 pages to "+cfg.Cache.Dir+"\n", out.String(), "Expected the number of pages fetched")
+
+	mockFetchEssay(1)
+	out.Reset()
+	assert.Nil(t, Extract(context.Background()), "Expected the pages to be read from the cache")
+	text := out.String()
+	assert.True(t, strings.HasPrefix(text, "# https://example.com/essay-1\n\n"), "Expected the text under the url of the page")
+	assert.Less(t, strings.Index(text, "essay-2"), strings.Index(text, "essay-3"), "Expected the pages in the order of the urls")
+	assert.NotContains(t, text, "<p>", "Expected the text without markup")
+}
+
+// Test the cached pages are used while fresh, revalidated once stale, and only read offline
+func TestOpenPageCache(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.Cache.Dir = t.TempDir()
+	config.Set(cfg)
+	var requests []string
+	externalsFetch = func(_ context.Context, url, etag, _ string) (externals.Response, error) {
+		requests = append(requests, etag)
+		if etag == `"v1"` {
+			return externals.Response{NotModified: true}, nil
+		}
+		return externals.Response{Body: io.NopCloser(strings.NewReader("<p>page</p>")), Header: http.Header{"Etag": {`"v1"`}}}, nil
+	}
+	defer unMockFetchEssay()
+	read := func() string {
+		body, err := openPage(context.Background(), "https://example.com/essay-1")
+		assert.Nil(t, err, "Expected the page")
+		if err != nil {
+			return ""
+		}
+		defer body.Close()
+		page, _ := io.ReadAll(body)
+		return string(page)
+	}
+
+	assert.Equal(t, "<p>page</p>", read(), "Expected the page fetched")
+	assert.Equal(t, "<p>page</p>", read(), "Expected the cached page")
+	assert.Equal(t, []string{""}, requests, "Expected the fresh page not to be fetched again")
+
+	cfg.Cache.TTL = "0s"
+	config.Set(cfg)
+	assert.Equal(t, "<p>page</p>", read(), "Expected the revalidated page")
+	assert.Equal(t, []string{"", `"v1"`}, requests, "Expected a conditional request for the stale page")
+
+	cfg.Cache.Offline = true
+	config.Set(cfg)
+	assert.Equal(t, "<p>page</p>", read(), "Expected the stale page offline")
+	_, err := openPage(context.Background(), "https://example.com/essay-2")
+	var permanent *backoff.PermanentError
+	assert.True(t, errors.As(err, &permanent), "Expected a page which is not cached to fail without retries")
+	assert.Equal(t, 2, len(requests), "Expected no request offline")
+}
+
+// Test a document larger than the maximum page size fails without being retried
+func TestReadDocumentMaxSize(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	requests := 0
+	externalsFetchEssay = func(_ context.Context, _, _ string) (io.ReadCloser, error) {
+		requests++
+		return io.NopCloser(strings.NewReader("<p>a page of seven words in it</p>")), nil
+	}
+	defer unMockFetchEssay()
+	maxPageSize = 16
+	defer func() { maxPageSize = 64 << 20 }()
+
+	var read atomic.Int64
+	_, _, err := readDocument(context.Background(), "https://example.com/essay-1", nil, &read)
+	assert.EqualError(t, err, "the document is larger than 16 B", "Expected the document to be too large")
+	assert.Equal(t, 1, requests, "Expected the document not to be fetched again")
+	assert.Equal(t, int64(17), read.Load(), "Expected the document to be read up to the maximum size")
+
+	maxPageSize = 64
+	page, _, err := readDocument(context.Background(), "https://example.com/essay-1", nil, &read)
+	assert.Nil(t, err, "Expected no error below the maximum size")
+	assert.Equal(t, "<p>a page of seven words in it</p>", string(page), "Expected the whole document")
+}
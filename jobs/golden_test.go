@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/externals"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const goldenFilePath = "testdata/result.golden.json"
+
+// mockFetchPages serves the pages of testdata/pages named after the last segment of the url
+func mockFetchPages() {
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		return os.Open(path.Join("testdata", "pages", path.Base(url)+".html"))
+	}
+	externalsFetch = func(ctx context.Context, url, _, _ string) (externals.Response, error) {
+		body, err := externalsFetchEssay(ctx, "GET", url)
+		return externals.Response{Body: body}, err
+	}
+}
+
+// runGolden runs the worker pool on the test pages and returns what it printed
+func runGolden(t *testing.T, workers int) []byte {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.DefaultFilePath = "testdata/urls.txt"
+	cfg.ResultLength = 5
+	cfg.WebScrapper.Count = workers
+	cfg.Tokenizer.Count = workers
+	config.Set(cfg)
+
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.Nil(t, StartWorkerPool(context.Background()), "Expected no error from StartWorkerPool")
+	return out.Bytes()
+}
+
+// Test the output is byte-identical to the golden file whatever the number of workers
+func TestStartWorkerPoolGolden(t *testing.T) {
+	if *update {
+		assert.Nil(t, os.WriteFile(goldenFilePath, runGolden(t, 1), 0644), "Expected no error updating the golden file")
+	}
+	golden, err := os.ReadFile(goldenFilePath)
+	assert.Nil(t, err, "Expected the golden file to exist, run the tests with -update to create it")
+
+	for _, workers := range []int{1, 2, 4, 8} {
+		for run := 0; run < 3; run++ {
+			assert.Equal(t, string(golden), string(runGolden(t, workers)), "Output differs from %s with %d workers", goldenFilePath, workers)
+		}
+	}
+}
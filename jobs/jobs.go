@@ -1,153 +1,451 @@
 package jobs
 
 import (
-	"container/heap"
+	"bytes"
 	"context"
 	"fmt"
-	"github.com/PuerkitoBio/goquery"
-	"github.com/cenkalti/backoff/v4"
 	"github.com/joshy-joy/essay-word-counter/config"
 	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/export"
 	"github.com/joshy-joy/essay-word-counter/externals"
-	"github.com/joshy-joy/essay-word-counter/utils"
-	"github.com/joshy-joy/essay-word-counter/utils/minheap"
+	"github.com/joshy-joy/essay-word-counter/extract"
+	"github.com/joshy-joy/essay-word-counter/language"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/output"
+	"github.com/joshy-joy/essay-word-counter/render"
+	"github.com/joshy-joy/essay-word-counter/report"
+	"github.com/joshy-joy/essay-word-counter/sources"
+	"github.com/joshy-joy/essay-word-counter/tokens"
+	"github.com/joshy-joy/essay-word-counter/utils/sentence"
+	"github.com/joshy-joy/essay-word-counter/utils/textstats"
+	"gopkg.in/yaml.v3"
+	"io"
 	"log"
-	"regexp"
+	"os"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 )
 
 var (
-	utilsReadFile       = utils.ReadFile
+	sourcesRead         = sources.Read
 	externalsFetchEssay = externals.FetchEssay
+	externalsFetch      = externals.Fetch
+	// stdout receives the result of a run and stderr its progress
+	stdout io.Writer = os.Stdout
+	stderr io.Writer = os.Stderr
+	// now is the clock of the run summary
+	now = time.Now
 )
-var (
-	wordFreqMap = make(map[string]int)
-	wordFreqMux sync.Mutex
-)
+
+// previewLength is the number of characters of extracted text kept for the HTML report
+const previewLength = 500
+
+// pages holds what became of every url of the input, by its index. An index is
+// only written by the worker handling its url, holding mu for reading so that a
+// checkpoint, holding it for writing, sees the pages and the counters agree.
+type pages struct {
+	mu    sync.RWMutex
+	urls  []string
+	stats []models.DocumentStats
+	// failures holds the reason a url could not be counted, empty when it was
+	failures []string
+	// previews holds the beginning of the text extracted from every page
+	previews []string
+	// progress counts the urls of the run and what was done with them
+	progress *progress
+}
+
+func newPages(n int) *pages {
+	return &pages{stats: make([]models.DocumentStats, n), failures: make([]string, n), previews: make([]string, n), progress: newProgress()}
+}
+
+// fail records why the url at index could not be counted
+func (p *pages) fail(index int, reason string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	p.failures[index] = reason
+	p.progress.failed.Add(1)
+}
+
+// done reports whether the url at index has been counted or has failed
+func (p *pages) done(index int) bool {
+	return p.stats[index].URL != constants.Empty || p.failures[index] != constants.Empty
+}
 
 func StartWorkerPool(ctx context.Context) error {
+	return countAndWrite(ctx, countCheckpointed)
+}
 
-	urls, err := utilsReadFile(config.Get().DefaultFilePath)
+// countAndWrite reads the urls, counts their pages with countPages, and writes
+// the result, the export, the charts and the report. When the run was stopped, or
+// exceeded its maximum duration, before every url was counted, the partial result
+// is written and an error returned.
+func countAndWrite(ctx context.Context, countPages func(ctx context.Context, urls []string) (*wordCounter, *pages, error)) error {
+	started := now()
+	ctx, cancel := withTimeout(ctx, "maximum duration", config.Get().Timeouts.MaxDuration)
+	defer cancel()
+	list, err := sourcesRead(ctx, sources.Configured())
 	if err != nil {
 		return err
 	}
+	urls := list.URLs
 
-	jobChan := make(chan string, len(urls))
-	h := minheap.NewMinHeap()
-	heap.Init(h)
+	// open the output file first so that a wrong path fails before scraping
+	w, file, err := createOutput()
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		defer file.Close()
+	}
 
-	var wg sync.WaitGroup
+	counter, pages, err := countPages(ctx, urls)
+	if err != nil {
+		return err
+	}
+	result := newResult(counter, pages)
+	result.Rejected = list.Rejected
+	if err := output.Write(w, config.Get().OutputFormat, result); err != nil {
+		return err
+	}
+	if path := config.Get().ExportPath; path != constants.Empty {
+		v, err := vocabulary(counter, pages.stats)
+		if err != nil {
+			return err
+		}
+		if err := export.Write(path, v); err != nil {
+			return err
+		}
+	}
+	for _, path := range config.Get().Render.Paths {
+		if err := render.Write(path, result.TopWords, config.Get().Render.Seed); err != nil {
+			return err
+		}
+	}
+	if path := config.Get().HTMLReportPath; path != constants.Empty {
+		if err := writeReport(path, result, pages, started); err != nil {
+			return err
+		}
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	if result.Incomplete {
+		return fmt.Errorf("the run was stopped, %d of %d urls were not counted: %w", len(result.Pending), len(pages.urls), context.Cause(ctx))
+	}
+	// the result is written, but a run counting nothing has failed
+	if len(result.Documents) == 0 {
+		return fmt.Errorf("no document was counted, %d urls failed and %d were rejected", len(result.Failures), len(result.Rejected))
+	}
+	return nil
+}
+
+// Count scrapes the urls and returns the result of counting their words
+func Count(ctx context.Context, urls []string) (models.Result, error) {
+	counter, pages, err := countPages(ctx, urls)
+	if err != nil {
+		return models.Result{}, err
+	}
+	return newResult(counter, pages), nil
+}
+
+// newResult returns the result of counting the pages
+func newResult(counter *wordCounter, pages *pages) models.Result {
+	documents := make([]models.DocumentStats, 0, len(pages.urls))
+	var failures []models.Failure
+	var pending []string
+	for i, s := range pages.stats {
+		if !pages.done(i) {
+			pending = append(pending, pages.urls[i])
+		}
+		// skip the essays which could not be scraped
+		if s.URL != constants.Empty {
+			documents = append(documents, s)
+		}
+		if reason := pages.failures[i]; reason != constants.Empty {
+			failures = append(failures, models.Failure{URL: pages.urls[i], Reason: reason})
+		}
+	}
+
+	return models.Result{
+		TopWords:      counter.words.top(config.Get().ResultLength),
+		Approximation: counter.words.approximation(),
+		Languages:     languageResults(counter),
+		Documents:     documents,
+		Failures:      failures,
+		Incomplete:    len(pending) > 0,
+		Pending:       pending,
+	}
+}
+
+// createOutput returns the writer the result goes to, the configured output file
+// or stdout, in which case the file is nil
+func createOutput() (io.Writer, *os.File, error) {
+	path := config.Get().OutputPath
+	if path == constants.Empty {
+		return stdout, nil, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, file, nil
+}
+
+// countPages scrapes the urls and counts the words of their pages
+func countPages(ctx context.Context, urls []string) (*wordCounter, *pages, error) {
+	pages := newPages(len(urls))
+	pages.urls = urls
+	counters := newCounters()
+	scrapeAndCount(ctx, pages, counters)
+	counter, err := mergeCounters(counters)
+	return counter, pages, err
+}
+
+// scrapeAndCount scrapes the urls of pages which are not done yet, and counts the
+// words of their pages with a tokenizer per counter
+func scrapeAndCount(ctx context.Context, pages *pages, counters []*wordCounter) {
+	urls := pages.urls
+	urlChan := make(chan int, len(urls))
+	for i := range urls {
+		if !pages.done(i) {
+			urlChan <- i
+		}
+	}
+	close(urlChan)
+	pages.progress.total.Add(int64(len(urlChan)))
+	defer reportProgress(pages.progress)()
 
-	// Start scraping workers
+	// the documents hold an open response body, so only a few of them wait for a tokenizer
+	jobChan := make(chan models.Document, len(counters))
+
+	// Start scraping workers, each one taking the next url from urlChan
+	var wg sync.WaitGroup
+	wg.Add(len(urlChan))
 	for i := 0; i < config.Get().WebScrapper.Count; i++ {
-		wg.Add(1)
 		go func() {
-			for _, url := range urls {
-				scrapper(ctx, url, jobChan, &wg)
+			for i := range urlChan {
+				scrapper(ctx, i, urls[i], jobChan, &wg, pages)
 			}
-			close(jobChan)
 		}()
 	}
+	// No more documents once every url has been scraped
+	go func() {
+		wg.Wait()
+		close(jobChan)
+	}()
 
 	// Start word processing workers
-	for i := 0; i < config.Get().Tokenizer.Count; i++ {
-		wg.Add(1)
-		go tokenizer(jobChan, &wg, h)
-	}
-
-	wg.Wait()
-
-	result := make([]minheap.Heap, h.Len())
-	for i := 0; h.Len() > 0; i++ {
-		result[i] = heap.Pop(h).(minheap.Heap)
-	}
+	tokenize(jobChan, pages, counters)
+}
 
-	formatterJson, err := utils.PrettyPrintJSON(result)
+// writeReport writes the HTML report of the run, with the preview of every page counted
+func writeReport(path string, result models.Result, pages *pages, started time.Time) error {
+	cfg, err := yaml.Marshal(config.Get())
 	if err != nil {
 		return err
 	}
-	fmt.Println(formatterJson)
-	return nil
+	r := report.Report{
+		Result:   result,
+		URLs:     len(pages.urls),
+		Started:  started,
+		Duration: now().Sub(started),
+		Config:   string(cfg),
+		Seed:     config.Get().Render.Seed,
+	}
+	for i, s := range pages.stats {
+		if s.URL != constants.Empty {
+			r.Previews = append(r.Previews, report.Preview{URL: s.URL, Language: s.Language, Text: pages.previews[i]})
+		}
+	}
+	return report.Write(path, r)
 }
 
-// Worker function to process each URL
-func scrapper(ctx context.Context, url string, jobChan chan string, wg *sync.WaitGroup) {
+// Worker function to process each URL, recording why it failed in pages. Once ctx
+// is done the url is left pending, the documents already opened being still read
+// to the end by the tokenizers. The url timeout bounds the requests and the reading
+// of the page with their retries, the time the document waits for a tokenizer
+// being not counted.
+func scrapper(ctx context.Context, index int, url string, jobChan chan models.Document, wg *sync.WaitGroup, pages *pages) {
 	defer wg.Done()
+	if ctx.Err() != nil {
+		return
+	}
+	page, format, err := readDocument(ctx, url, nil, &pages.progress.bytes)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to scrape %s after retries: %v", url, err)
+			pages.fail(index, err.Error())
+		}
+		return
+	}
+	jobChan <- models.Document{Index: index, URL: url, Format: format, Body: io.NopCloser(bytes.NewReader(page))}
+}
 
-	operation := func() error {
-		body, err := externalsFetchEssay(ctx, "GET", url)
+// Function to count words from each post and compute its statistics
+func tokenizer(jobChan chan models.Document, wg *sync.WaitGroup, counter *wordCounter, pages *pages) {
+	defer wg.Done()
+	for doc := range jobChan {
+		// the document timeout starts once a tokenizer takes the document
+		ctx, cancel := withTimeout(context.Background(), "document timeout", config.Get().Timeouts.Document)
+		counted, err := processDocument(ctx, doc)
+		cancel()
 		if err != nil {
-			log.Printf("error getting url response")
-			return err
+			log.Printf("Failed to process %s: %v", doc.URL, err)
+			pages.fail(doc.Index, err.Error())
+			continue
+		}
+		pages.mu.RLock()
+		counter.addDocument(doc.Index, counted.stats.Language, counted.words)
+		pages.stats[doc.Index] = counted.stats
+		pages.previews[doc.Index] = counted.preview
+		pages.mu.RUnlock()
+		pages.progress.done.Add(1)
+		pages.progress.tokens.Add(int64(counted.stats.Words))
+	}
+}
+
+// countedDocument is the statistics, the preview and the word counts of a document
+type countedDocument struct {
+	stats   models.DocumentStats
+	preview string
+	words   map[string]int
+}
+
+// Stream the document from the extractor of its format to the pipeline of its language,
+// one paragraph at a time. The words are counted in a map local to the document, added
+// to a counter by the tokenizer only once the whole document has been read.
+// It also returns the first characters of the extracted text as a preview. Once ctx
+// is done, the document is left after the paragraph being counted and the cause of
+// ctx returned.
+func processDocument(ctx context.Context, doc models.Document) (countedDocument, error) {
+	defer doc.Body.Close()
+	extractor, err := extract.New(doc.Format, doc.Body)
+	if err != nil {
+		return countedDocument{}, err
+	}
+	docStats := textstats.NewCounter()
+	words := make(map[string]int)
+	var preview []string
+	previewLen := 0
+
+	var p *language.Pipeline
+	process := func(paragraph string) {
+		sentences := getSentences(paragraph, p)
+		docStats.AddParagraph(sentences)
+		for _, sentence := range sentences {
+			for _, word := range sentence {
+				if word, ok := normalizeWord(p, word); ok {
+					words[word]++
+				}
+			}
+		}
+	}
+
+	// the first paragraphs are kept until there is enough text to detect the language
+	var sample []string
+	sampleLen := 0
+	detect := func() {
+		p = language.For(detectLanguage(strings.Join(sample, sentence.ParagraphBreak)))
+		for _, paragraph := range sample {
+			process(paragraph)
+		}
+		sample = nil
+	}
+
+	for {
+		paragraph, err := extractor.Next()
+		if ctx.Err() != nil {
+			return countedDocument{}, context.Cause(ctx)
+		}
+		if err == io.EOF {
+			break
 		}
-		doc, err := goquery.NewDocumentFromReader(body)
 		if err != nil {
-			log.Printf("Failed to parse page %s: %v", url, err)
-			return err
+			return countedDocument{}, err
+		}
+		if previewLen < previewLength {
+			preview = append(preview, paragraph)
+			previewLen += utf8.RuneCountInString(paragraph)
 		}
+		if p != nil {
+			process(paragraph)
+			continue
+		}
+		sample = append(sample, paragraph)
+		sampleLen += utf8.RuneCountInString(paragraph)
+		if sampleLen >= language.SampleSize {
+			detect()
+		}
+	}
+	if p == nil {
+		detect()
+	}
 
-		var content strings.Builder
-		doc.Find("body").Each(func(i int, s *goquery.Selection) {
-			// Iterate over all the child nodes of the body tag
-			content.WriteString(extractText(s))
-		})
+	return countedDocument{
+		stats:   models.DocumentStats{URL: doc.URL, Language: p.Language, Stats: docStats.Stats()},
+		preview: truncate(strings.Join(preview, sentence.ParagraphBreak), previewLength),
+		words:   words,
+	}, nil
+}
 
-		jobChan <- content.String()
-		return nil
+// truncate keeps the first n characters of the text, marking the cut with an ellipsis
+func truncate(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
 	}
+	return string([]rune(text)[:n]) + "…"
+}
 
-	// Retry on failure with exponential backoff
-	err := backoff.Retry(operation, backoff.WithMaxRetries(backoff.NewExponentialBackOff(), 5))
-	if err != nil {
-		log.Printf("Failed to scrape %s after retries: %v", url, err)
+// Apply the stop words, stemming and minimum length filters of the pipeline to a word
+func normalizeWord(p *language.Pipeline, word string) (string, bool) {
+	if config.Get().Language.RemoveStopWords && p.IsStopWord(word) {
+		return constants.Empty, false
+	}
+	if config.Get().Language.Stem && p.Stem != nil {
+		word = p.Stem(word)
+	}
+	// condition: to filter words with minimum length
+	minLength := config.Get().WordMinLength
+	if p.MinWordLength > 0 {
+		minLength = p.MinWordLength
+	}
+	if utf8.RuneCountInString(word) < minLength {
+		return constants.Empty, false
 	}
+	return word, true
 }
 
-// Function to count words from each post
-func tokenizer(jobChan chan string, wg *sync.WaitGroup, h *minheap.MinHeap) {
-	defer wg.Done()
-	for content := range jobChan {
-		words := getWords(content)
-		wordFreqMux.Lock()
-		for _, word := range words {
-			// condition: to filter words with minimum length
-			if len(word) >= config.Get().WordMinLength {
-				wordFreqMap[word]++
-				// Add the current number to the heap
-				heap.Push(h, minheap.Heap{Word: word, Count: wordFreqMap[word]})
-
-				// If heap size exceeds 10, remove the smallest element
-				if h.Len() > config.Get().ResultLength {
-					heap.Pop(h)
-				}
-			}
-		}
-		wordFreqMux.Unlock()
+// Detect the language of the text, falling back to the configured default
+func detectLanguage(text string) string {
+	if !config.Get().Language.Detect {
+		return config.Get().Language.Default
 	}
+	lang := language.Detect(text)
+	if lang == language.Unknown {
+		return config.Get().Language.Default
+	}
+	return lang
 }
 
-// Extract and clean words from the content
-func getWords(content string) []string {
-	re := regexp.MustCompile(`[^\w\s]+`)
-	cleanContent := re.ReplaceAllString(content, "")
-	return strings.Fields(strings.ToLower(cleanContent))
+// Extract words from the content according to the configured token rules
+func getWords(content string, p *language.Pipeline) []string {
+	rules := config.Get().Tokens
+	rules.Elisions = p.Elisions
+	return tokens.Tokenize(content, rules, p.Segment)
 }
 
-// Recursive function to extract text from HTML nodes
-func extractText(s *goquery.Selection) string {
-	var text strings.Builder
-	// Loop through each child node
-	s.Contents().Each(func(i int, child *goquery.Selection) {
-		if goquery.NodeName(child) == "#text" {
-			// If it's a text node, append its content
-			if strings.TrimSpace(child.Text()) != constants.Empty {
-				text.WriteString(strings.TrimSpace(child.Text()) + " ")
-			}
-		} else {
-			// If it's an element node, extract its child nodes recursively
-			text.WriteString(extractText(child))
+// Split a paragraph into sentences, each sentence being the list of its words
+func getSentences(paragraph string, p *language.Pipeline) [][]string {
+	var sentences [][]string
+	for _, s := range sentence.Split(paragraph) {
+		if words := getWords(s, p); len(words) > 0 {
+			sentences = append(sentences, words)
 		}
-	})
-	return text.String()
+	}
+	return sentences
 }
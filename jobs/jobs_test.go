@@ -1,36 +1,43 @@
 package jobs
 
 import (
-	"container/heap"
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
-	"github.com/PuerkitoBio/goquery"
 	"github.com/joshy-joy/essay-word-counter/config"
-	"github.com/joshy-joy/essay-word-counter/utils"
-	"github.com/joshy-joy/essay-word-counter/utils/minheap"
+	"github.com/joshy-joy/essay-word-counter/externals"
+	"github.com/joshy-joy/essay-word-counter/language"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/sources"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"os"
+	"path"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 const devConfigFilePath = "../resources/dev/config.yml"
 
-func mockUtilsReadFile(code int) {
-	utilsReadFile = func(_ string) ([]string, error) {
+func mockSourcesRead(code int) {
+	sourcesRead = func(_ context.Context, _ sources.Input) (sources.List, error) {
 		switch code {
 		case 1:
-			return nil, errors.New("error reading text file")
+			return sources.List{}, errors.New("error reading text file")
 		default:
-			return []string{"https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/",
-				"https://www.engadget.com/2019/08/24/trump-tries-to-overturn-ruling-stopping-him-from-blocking-twitte/"}, nil
+			return sources.List{URLs: []string{"https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/",
+				"https://www.engadget.com/2019/08/24/trump-tries-to-overturn-ruling-stopping-him-from-blocking-twitte/"}}, nil
 		}
 	}
 }
 
-func unMockUtilsReadFile() {
-	utilsReadFile = utils.ReadFile
+func unMockSourcesRead() {
+	sourcesRead = sources.Read
 }
 
 func mockFetchEssay(code int) {
@@ -45,89 +52,615 @@ func mockFetchEssay(code int) {
 }
 
 func unMockFetchEssay() {
-	utilsReadFile = utils.ReadFile
+	externalsFetchEssay = externals.FetchEssay
+	externalsFetch = externals.Fetch
 }
 
 // Test the scrapper function to ensure it processes pages correctly
 func TestScrapper(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
 	ctx := context.Background()
-	jobChan := make(chan string, 1)
+	jobChan := make(chan models.Document, 1)
 	var wg sync.WaitGroup
 	wg.Add(1)
 	mockFetchEssay(0)
 	defer unMockFetchEssay()
 
-	go scrapper(ctx, "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/", jobChan, &wg)
-	content := <-jobChan
+	go scrapper(ctx, 0, "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/", jobChan, &wg, newPages(1))
+	doc := <-jobChan
 	wg.Wait()
 
-	assert.Equal(t, "Test content for test content test ", content, "Expected correct content from scraper")
+	body, _ := io.ReadAll(doc.Body)
+	assert.Equal(t, "<html><body><p>Test content for test content test </p></body></html>", string(body), "Expected correct content from scraper")
+	assert.Equal(t, "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/", doc.URL, "Expected the document to keep its url")
 }
 
 // Test scrapper for error handling
 func TestScrapperExternalError(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
 	ctx := context.Background()
-	jobChan := make(chan string, 2)
+	jobChan := make(chan models.Document, 2)
 	var wg sync.WaitGroup
 	wg.Add(1)
 	mockFetchEssay(1)
 	defer unMockFetchEssay()
-	go scrapper(ctx, "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/", jobChan, &wg)
-	close(jobChan)
+	pages := newPages(1)
+	go scrapper(ctx, 0, "https://www.engadget.com/2019/08/25/sony-and-yamaha-sc-1-sociable-cart/", jobChan, &wg, pages)
 	wg.Wait()
+	close(jobChan)
+	assert.Equal(t, "error getting url response", pages.failures[0], "Expected the reason of the failure")
+}
+
+// Test a page whose body fails while being read is fetched again
+func TestScrapperReadError(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer unMockFetchEssay()
+	attempts := 0
+	externalsFetchEssay = func(_ context.Context, _, _ string) (io.ReadCloser, error) {
+		attempts++
+		page := io.Reader(strings.NewReader("<html><body><p>Test content</p></body></html>"))
+		if attempts == 1 {
+			page = io.MultiReader(strings.NewReader("<html><body>"), iotest.ErrReader(errors.New("connection reset")))
+		}
+		return io.NopCloser(page), nil
+	}
+	jobChan := make(chan models.Document, 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	pages := newPages(1)
+	scrapper(context.Background(), 0, "https://example.com/essay-1", jobChan, &wg, pages)
+
+	doc := <-jobChan
+	body, _ := io.ReadAll(doc.Body)
+	assert.Equal(t, 2, attempts, "Expected the page to be fetched again")
+	assert.Equal(t, "<html><body><p>Test content</p></body></html>", string(body), "Expected the content of the second fetch")
+	assert.Empty(t, pages.failures[0], "Expected no failure")
+}
+
+// Test local documents are counted with the extractor of their format, a missing one failing without retries
+func TestCountLocalDocuments(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	mockFetchEssay(1)
+	defer unMockFetchEssay()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "essay.md")
+	assert.Nil(t, os.WriteFile(path, []byte("# Title\n\n```\ncode code code\n```\nSome *text* and [a link](https://example.com) text\n"), 0o644), "Expected no error writing the document")
+	document, _ := sources.FileURL(path)
+	missing, _ := sources.FileURL(filepath.Join(dir, "missing.txt"))
+
+	started := time.Now()
+	result, err := Count(context.Background(), []string{document, missing})
+	assert.Nil(t, err, "Expected no error counting the documents")
+	assert.Less(t, time.Since(started), time.Second, "Expected the missing document not to be retried")
+	assert.Equal(t, "text", result.TopWords[0].Word, "Expected the words of the document without its markup")
+	assert.Equal(t, 2, result.TopWords[0].Count, "Expected the words of the code block to be skipped")
+	assert.Equal(t, 1, len(result.Failures), "Expected the missing document to fail")
+	assert.Equal(t, missing, result.Failures[0].URL, "Expected the url of the missing document")
+}
+
+// helper to create a document from an html page
+func htmlDocument(index int, url, page string) models.Document {
+	return models.Document{Index: index, URL: url, Body: io.NopCloser(strings.NewReader(page))}
 }
 
 // Test tokenizer to ensure it counts words correctly
 func TestTokenizer(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
-	jobChan := make(chan string, 1)
-	h := minheap.NewMinHeap()
-	heap.Init(h)
+	jobChan := make(chan models.Document, 1)
+	counter := newWordCounter()
+	pages := newPages(1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	jobChan <- htmlDocument(0, "https://example.com", "<p>The joshy and the joy of joshy.</p><p>Mike and the joy of the sun with joshy</p>")
+	close(jobChan)
+
+	go tokenizer(jobChan, &wg, counter, pages)
+	wg.Wait()
+
+	stats := pages.stats
+	assert.Equal(t, "https://example.com", stats[0].URL, "Expected the stats to keep the document url")
+	assert.Equal(t, "en", stats[0].Language, "Expected the document to be detected as english")
+	assert.Equal(t, 16, stats[0].Words, "Expected 16 words in the document")
+	assert.Equal(t, 8, stats[0].UniqueWords, "Expected 8 unique words in the document")
+	assert.Equal(t, 2, stats[0].Sentences, "Expected 2 sentences in the document")
+	assert.Equal(t, 2, stats[0].Paragraphs, "Expected 2 paragraphs in the document")
+	assert.Equal(t, "The joshy and the joy of joshy.\n\nMike and the joy of the sun with joshy", pages.previews[0], "Expected the extracted text as preview")
+
+	top := counter.words.top(config.Get().ResultLength)
+	assert.Equal(t, 2, len(top), "Expected heap length to be 2")
+	assert.Equal(t, "the", top[0].Word, "Expected the top word to be 'the'")
+	assert.Equal(t, 4, top[0].Count, "Expected the count to be 4")
+	assert.Equal(t, "joshy", top[1].Word, "Expected the second word to be 'joshy'")
+	assert.Equal(t, 1, counter.documents["en"], "Expected one english document")
+	assert.Equal(t, 2, counter.languages["en"].count("joy"), "Expected 2 occurrences of 'joy' in english documents")
+}
+
+// Test tokenizer skips the documents which cannot be read
+func TestTokenizerReadError(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	jobChan := make(chan models.Document, 1)
+	counter := newWordCounter()
+	pages := newPages(1)
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	jobChan <- "joshy joy joshy mike joy sun joshy"
+	reader := io.MultiReader(strings.NewReader("<p>partial content</p>"), iotestErrReader{})
+	jobChan <- models.Document{URL: "https://example.com", Body: io.NopCloser(reader)}
 	close(jobChan)
 
-	go tokenizer(jobChan, &wg, h)
+	go tokenizer(jobChan, &wg, counter, pages)
 	wg.Wait()
 
-	assert.Equal(t, 2, h.Len(), "Expected heap length to be 2")
-	top := heap.Pop(h).(minheap.Heap)
-	assert.Equal(t, "joy", top.Word, "Expected the top word to be 'joy'")
-	assert.Equal(t, 2, top.Count, "Expected the count to be 2")
+	assert.Equal(t, models.DocumentStats{}, pages.stats[0], "Expected no stats for a document failing to be read")
+	assert.Equal(t, "connection reset", pages.failures[0], "Expected the reason of the failure")
+	assert.Empty(t, counter.words.top(config.Get().ResultLength), "Expected the words of a failed document not to be counted")
+}
+
+// reader failing after the content of the document
+type iotestErrReader struct{}
+
+func (iotestErrReader) Read(_ []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// Test runTokenizers merges the counts of every worker
+func TestRunTokenizers(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	cfg := config.Get()
+	cfg.Language.Detect = false
+	config.Set(cfg)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+
+	jobChan := make(chan models.Document, 3)
+	pages := newPages(3)
+	jobChan <- htmlDocument(0, "https://example.com/1", "<p>alpha beta alpha</p>")
+	jobChan <- htmlDocument(1, "https://example.com/2", "<p>alpha gamma</p>")
+	jobChan <- htmlDocument(2, "https://example.com/3", "<p>Les données et les chiffres sont là, alpha beta</p>")
+	close(jobChan)
+
+	counter, err := runTokenizers(jobChan, pages)
+	assert.Nil(t, err, "Expected no error merging the counts")
+
+	assert.Equal(t, 4, counter.words.count("alpha"), "Expected the merged count of 'alpha'")
+	assert.Equal(t, 2, counter.words.count("beta"), "Expected the merged count of 'beta'")
+	assert.Equal(t, 1, counter.words.count("gamma"), "Expected the merged count of 'gamma'")
+	assert.Equal(t, map[string]int{"en": 3}, counter.documents, "Expected the merged document counts")
+
+	languages := languageResults(counter)
+	assert.Equal(t, 1, len(languages), "Expected results for 1 language")
+	assert.Equal(t, 3, languages[0].Documents, "Expected 3 english documents")
+	assert.Nil(t, languages[0].Approximation, "Expected exact counts by default")
+}
+
+// Test runTokenizers merges approximate counts and reports their error bounds
+func TestRunTokenizersApproximate(t *testing.T) {
+	for _, algorithm := range []string{"spaceSaving", "countMinSketch"} {
+		_ = config.InitConfig(devConfigFilePath)
+		cfg := config.Get()
+		cfg.Language.Detect = false
+		cfg.Approximate.Enabled = true
+		cfg.Approximate.Algorithm = algorithm
+		cfg.Approximate.Epsilon = 0.01
+		config.Set(cfg)
+
+		jobChan := make(chan models.Document, 3)
+		pages := newPages(3)
+		jobChan <- htmlDocument(0, "https://example.com/1", "<p>alpha beta alpha</p>")
+		jobChan <- htmlDocument(1, "https://example.com/2", "<p>alpha gamma beta</p>")
+		jobChan <- htmlDocument(2, "https://example.com/3", "<p>alpha delta</p>")
+		close(jobChan)
+
+		counter, err := runTokenizers(jobChan, pages)
+		assert.Nil(t, err, "Expected no error merging the counts with %s", algorithm)
+
+		top := counter.words.top(2)
+		assert.Equal(t, []models.WordCount{{Rank: 1, Word: "alpha", Count: 4}, {Rank: 2, Word: "beta", Count: 2}}, top, "Expected the approximate top words with %s", algorithm)
+		approximation := counter.words.approximation()
+		assert.Equal(t, algorithm, approximation.Algorithm, "Expected the algorithm to be reported")
+		assert.Equal(t, 8, approximation.Words, "Expected every word to be counted with %s", algorithm)
+		assert.Equal(t, 0, approximation.MaxError, "Expected the error bound for 8 words with %s", algorithm)
+		assert.NotNil(t, languageResults(counter)[0].Approximation, "Expected the error bounds of the language with %s", algorithm)
+	}
+	_ = config.InitConfig(devConfigFilePath)
+}
+
+// Test normalizeWord with stop words and stemming enabled
+func TestNormalizeWord(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	p := language.For("en")
+	word, ok := normalizeWord(p, "essays")
+	assert.True(t, ok, "Expected the word to be kept")
+	assert.Equal(t, "essays", word, "Expected the word to be unchanged by default")
+
+	cfg := config.Get()
+	cfg.Language.RemoveStopWords = true
+	cfg.Language.Stem = true
+	config.Set(cfg)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+
+	_, ok = normalizeWord(p, "the")
+	assert.False(t, ok, "Expected stop words to be removed")
+	word, ok = normalizeWord(p, "essays")
+	assert.True(t, ok, "Expected the word to be kept")
+	assert.Equal(t, "essay", word, "Expected the word to be stemmed")
+}
+
+// Test detectLanguage falls back to the default language
+func TestDetectLanguage(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	assert.Equal(t, "fr", detectLanguage("Le chat est sur la table et il regarde les oiseaux dans le jardin."), "Expected french")
+	assert.Equal(t, "en", detectLanguage("42"), "Expected the default language for an undetectable text")
+}
+
+// Test the sample detecting the language of a document is measured in runes,
+// a paragraph of multibyte runes shorter than the sample not ending it
+func TestProcessDocumentSampleRunes(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	// 4400 bytes but 2200 runes without a letter
+	dashes := strings.Repeat("— ", 1100)
+	page := "<html><body><p>" + dashes + "</p><p>Le chat est sur la table et il regarde les oiseaux dans le jardin.</p></body></html>"
+	counted, err := processDocument(context.Background(), models.Document{URL: "https://example.com/essay-1", Format: "html", Body: io.NopCloser(strings.NewReader(page))})
+	assert.Nil(t, err, "Expected no error")
+	assert.Equal(t, "fr", counted.stats.Language, "Expected the language of the paragraphs following the dashes")
+}
+
+// Test approximate counts fail to be exported, checkpointed or merged with other
+// kinds of counts instead of panicking
+func TestApproximateCountsErrors(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	exact := newWordCounter()
+	exact.addDocument(0, "en", map[string]int{"essay": 1})
+	cfg := config.Get()
+	cfg.Approximate.Enabled = true
+	cfg.Approximate.Algorithm = "countMinSketch"
+	cfg.Approximate.Epsilon = 0.01
+	config.Set(cfg)
+	approximate := newWordCounter()
+	approximate.addDocument(0, "en", map[string]int{"essay": 1})
+
+	_, err := vocabulary(approximate, []models.DocumentStats{{URL: "https://example.com/1"}})
+	assert.EqualError(t, err, "the complete vocabulary cannot be exported in approximate mode", "Expected the export to be rejected")
+	pages := newPages(1)
+	pages.urls = []string{"https://example.com/1"}
+	_, err = snapshot(pages, []*wordCounter{approximate})
+	assert.EqualError(t, err, "counts cannot be checkpointed in approximate mode", "Expected the checkpoint to be rejected")
+	_, err = mergeCounters([]*wordCounter{exact, approximate})
+	assert.EqualError(t, err, "exact and approximate counts cannot be merged", "Expected counts of different kinds not to be merged")
+
+	// sketches of other dimensions do not merge
+	cfg.Approximate.Epsilon = 0.1
+	config.Set(cfg)
+	other := newWordCounter()
+	other.addDocument(0, "en", map[string]int{"essay": 1})
+	assert.NotNil(t, approximate.merge(other), "Expected sketches of other dimensions not to be merged")
+}
+
+// Test getSentences with a pipeline segmenting chinese words
+func TestGetSentencesSegmented(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	p := language.For("zh")
+	sentences := getSentences("北京是中国的首都。", p)
+	assert.Equal(t, [][]string{{"北京", "是", "中国", "的", "首都"}}, sentences, "Chinese sentences extracted incorrectly")
+
+	word, ok := normalizeWord(p, "北京")
+	assert.True(t, ok, "Expected two characters chinese words to be kept")
+	assert.Equal(t, "北京", word, "Expected the word to be unchanged")
 }
 
 // Test getWords to ensure proper word extraction
 func TestGetWords(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
 	content := "Hello, World! This is a test."
-	words := getWords(content)
+	words := getWords(content, language.For("en"))
 	expected := []string{"hello", "world", "this", "is", "a", "test"}
 
 	assert.Equal(t, expected, words, "Words extracted are incorrect")
 }
 
-// Test extractText function to handle HTML content correctly
-func TestExtractText(t *testing.T) {
+// Test getSentences to ensure words are grouped by sentence
+func TestGetSentences(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
-	html := "<div><p>Hello</p> <span>world!</span></div>"
-	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
-	text := extractText(doc.Selection)
-	expected := "Hello world! "
+	content := "Hello, World! Mr. Smith paid $3.5 for this... it was a test. Is it?"
+	sentences := getSentences(content, language.For("en"))
+	expected := [][]string{
+		{"hello", "world"},
+		{"mr", "smith", "paid", "3.5", "for", "this", "it", "was", "a", "test"},
+		{"is", "it"},
+	}
 
-	assert.Equal(t, expected, text, "Extracted text is incorrect")
+	assert.Equal(t, expected, sentences, "Sentences extracted are incorrect")
 }
 
 // Test StartWorkerPool with an error case
 func TestStartWorkerPoolReadFileErrorCase(t *testing.T) {
 	_ = config.InitConfig(devConfigFilePath)
 	ctx := context.Background()
-	mockUtilsReadFile(1)
-	defer unMockUtilsReadFile()
+	mockSourcesRead(1)
+	defer unMockSourcesRead()
 
 	err := StartWorkerPool(ctx)
 	assert.NotNil(t, err, "Expected an error from StartWorkerPool due to file read failure")
 }
+
+// Test StartWorkerPool with several scrapers sharing the urls
+func TestStartWorkerPoolSuccess(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	ctx := context.Background()
+	mockSourcesRead(0)
+	defer unMockSourcesRead()
+	mockFetchEssay(0)
+	defer unMockFetchEssay()
+
+	err := StartWorkerPool(ctx)
+	assert.Nil(t, err, "Expected no error from StartWorkerPool")
+}
+
+// Test topWords reports descending counts with ties in lexical order
+func TestTopWordsTies(t *testing.T) {
+	freq := map[string]int{"delta": 2, "alpha": 2, "omega": 5, "beta": 2, "gamma": 1}
+	expected := []models.WordCount{{Rank: 1, Word: "omega", Count: 5}, {Rank: 2, Word: "alpha", Count: 2}, {Rank: 3, Word: "beta", Count: 2}}
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, topWords(freq, 3), "Expected the same order on every run")
+	}
+}
+
+// onFirstRead calls f before the first read of the body
+type onFirstRead struct {
+	io.ReadCloser
+	once *sync.Once
+	f    func()
+}
+
+func (r onFirstRead) Read(b []byte) (int, error) {
+	r.once.Do(r.f)
+	return r.ReadCloser.Read(b)
+}
+
+// Test a stopped run counts the documents already being read, leaves the urls left
+// pending instead of failed, stops retrying and cancels the requests waiting for
+// their response
+func TestCountPagesCancelled(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.WebScrapper.Count = 1
+	config.Set(cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockFetchPages()
+	defer unMockFetchEssay()
+	fetch := externalsFetchEssay
+	externalsFetchEssay = func(ctx context.Context, method, url string) (io.ReadCloser, error) {
+		body, err := fetch(ctx, method, url)
+		if url == checkpointURLs[1] && err == nil {
+			return onFirstRead{body, &sync.Once{}, cancel}, nil
+		}
+		return body, err
+	}
+
+	counter, pages, err := countPages(ctx, checkpointURLs)
+	assert.Nil(t, err, "Expected no error counting the pages")
+	result := newResult(counter, pages)
+	assert.Len(t, result.Documents, 2, "Expected the documents opened to be counted")
+	assert.Empty(t, result.Failures, "Expected no url to fail")
+	assert.True(t, result.Incomplete, "Expected the result to be incomplete")
+	assert.Equal(t, checkpointURLs[2:], result.Pending, "Expected the url left to be pending")
+	assert.Equal(t, int64(2), pages.progress.done.Load(), "Expected the progress of the urls counted")
+	assert.Positive(t, pages.progress.bytes.Load(), "Expected the progress of the bytes read")
+	assert.Positive(t, pages.progress.tokens.Load(), "Expected the progress of the tokens counted")
+
+	// a failing url is not retried once the run is stopped
+	mockFetchEssay(1)
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	started := time.Now()
+	counter, pages, _ = countPages(ctx, checkpointURLs[:1])
+	assert.Less(t, time.Since(started), time.Second, "Expected the retries to stop")
+	assert.Equal(t, checkpointURLs[:1], newResult(counter, pages).Pending, "Expected the url to be pending")
+
+	// a request without response is cancelled once the run is stopped
+	externalsFetchEssay = func(ctx context.Context, _, _ string) (io.ReadCloser, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	started = time.Now()
+	counter, pages, _ = countPages(ctx, checkpointURLs[:1])
+	assert.Less(t, time.Since(started), time.Second, "Expected the request to be cancelled")
+	result = newResult(counter, pages)
+	assert.Equal(t, checkpointURLs[:1], result.Pending, "Expected the url to be pending")
+	assert.Empty(t, result.Failures, "Expected no url to fail")
+}
+
+// Test a url failing for longer than the url timeout fails with the timeout
+// instead of being retried
+func TestScrapperURLTimeout(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.Timeouts.URL = "200ms"
+	config.Set(cfg)
+	mockFetchEssay(1)
+	defer unMockFetchEssay()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	pages := newPages(1)
+	started := time.Now()
+	scrapper(context.Background(), 0, "https://example.com/essay-1", make(chan models.Document), &wg, pages)
+	assert.Less(t, time.Since(started), time.Second, "Expected the retries to stop at the timeout")
+	assert.Equal(t, "url timeout of 200ms exceeded", pages.failures[0], "Expected the timeout as the reason of the failure")
+}
+
+// Test a document which is not counted within the document timeout fails
+func TestProcessDocumentTimeout(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	ctx, cancel := withTimeout(context.Background(), "document timeout", "1ns")
+	defer cancel()
+	<-ctx.Done()
+	page := "<html><body>" + strings.Repeat("<p>Test content for test content test</p>", 100) + "</body></html>"
+
+	_, err := processDocument(ctx, models.Document{URL: "https://example.com/essay-1", Format: "html", Body: io.NopCloser(strings.NewReader(page))})
+	assert.EqualError(t, err, "document timeout of 1ns exceeded", "Expected the document timeout")
+}
+
+// Test the time a document waits for a tokenizer is not counted by the url timeout
+func TestScrapperURLTimeoutQueued(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.Timeouts.URL = "50ms"
+	config.Set(cfg)
+	mockFetchEssay(0)
+	defer unMockFetchEssay()
+
+	jobChan := make(chan models.Document)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	pages := newPages(1)
+	go scrapper(context.Background(), 0, "https://example.com/essay-1", jobChan, &wg, pages)
+	time.Sleep(200 * time.Millisecond)
+	doc := <-jobChan
+	wg.Wait()
+
+	counted, err := processDocument(context.Background(), doc)
+	assert.Nil(t, err, "Expected no error reading the queued document")
+	assert.Positive(t, counted.stats.Stats.Words, "Expected the words of the queued document")
+	assert.Empty(t, pages.failures[0], "Expected no failure")
+}
+
+// Test a run exceeding its maximum duration writes the result of the urls done
+// and reports the others
+func TestCountAndWriteMaxDuration(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	cfg := config.Get()
+	cfg.WebScrapper.Count = 1
+	cfg.Timeouts.MaxDuration = "100ms"
+	config.Set(cfg)
+	sourcesRead = func(_ context.Context, _ sources.Input) (sources.List, error) {
+		return sources.List{URLs: checkpointURLs}, nil
+	}
+	defer unMockSourcesRead()
+	mockFetchPages()
+	defer unMockFetchEssay()
+	fetch := externalsFetchEssay
+	// the maximum duration is exceeded while the first page is read
+	externalsFetchEssay = func(ctx context.Context, method, url string) (io.ReadCloser, error) {
+		body, err := fetch(ctx, method, url)
+		if err != nil {
+			return nil, err
+		}
+		return onFirstRead{body, &sync.Once{}, func() { time.Sleep(200 * time.Millisecond) }}, nil
+	}
+	var out strings.Builder
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+
+	err := countAndWrite(context.Background(), countCheckpointed)
+	assert.EqualError(t, err, "the run was stopped, 2 of 3 urls were not counted: maximum duration of 100ms exceeded", "Expected the run to stop at its maximum duration")
+	var result models.Result
+	assert.Nil(t, json.Unmarshal([]byte(out.String()), &result), "Expected the result to be written")
+	assert.Len(t, result.Documents, 1, "Expected the url fetched to be counted")
+	assert.Equal(t, checkpointURLs[1:], result.Pending, "Expected the urls left to be pending")
+}
+
+// Test the result is written to the output file in the configured format
+func TestStartWorkerPoolOutputFile(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	outputPath := path.Join(t.TempDir(), "result.csv")
+	cfg := config.Get()
+	cfg.DefaultFilePath = "testdata/urls.txt"
+	cfg.OutputFormat = "csv"
+	cfg.OutputPath = outputPath
+	config.Set(cfg)
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.Nil(t, StartWorkerPool(context.Background()), "Expected no error from StartWorkerPool")
+	content, err := os.ReadFile(outputPath)
+	assert.Nil(t, err, "Expected the output file to be written")
+	expected := "language,rank,word,count\nall,1,the,8\nall,2,les,6\nen,1,the,8\nen,2,screens,6\nfr,1,les,6\nfr,2,voitures,2\n"
+	assert.Equal(t, expected, string(content), "CSV output mismatch")
+}
+
+// Test the complete vocabulary is kept with the document frequency of every word
+func TestStartWorkerPoolExport(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	exportPath := path.Join(t.TempDir(), "words.csv")
+	cfg := config.Get()
+	cfg.DefaultFilePath = "testdata/urls.txt"
+	cfg.ExportPath = exportPath
+	config.Set(cfg)
+	stdout = io.Discard
+	defer func() { stdout = os.Stdout }()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.Nil(t, StartWorkerPool(context.Background()), "Expected no error from StartWorkerPool")
+	content, err := os.ReadFile(exportPath)
+	assert.Nil(t, err, "Expected the vocabulary to be exported")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Equal(t, "1,the,8,2", lines[1], "Expected 'the' first, found in 2 documents")
+	assert.Equal(t, "3,screens,6,2", lines[3], "Expected 'screens' found in 2 documents")
+	assert.Equal(t, 56, len(lines)-1, "Expected every word of the vocabulary")
+}
+
+// Test the configured charts are drawn from the top words
+func TestStartWorkerPoolRender(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	dir := t.TempDir()
+	cfg := config.Get()
+	cfg.DefaultFilePath = "testdata/urls.txt"
+	cfg.Render.Paths = []string{path.Join(dir, "wordcloud.svg"), path.Join(dir, "bars.png")}
+	config.Set(cfg)
+	stdout = io.Discard
+	defer func() { stdout = os.Stdout }()
+	mockFetchPages()
+	defer unMockFetchEssay()
+
+	assert.Nil(t, StartWorkerPool(context.Background()), "Expected no error from StartWorkerPool")
+	cloud, err := os.ReadFile(cfg.Render.Paths[0])
+	assert.Nil(t, err, "Expected the word cloud to be drawn")
+	assert.Contains(t, string(cloud), ">the</text>", "Expected the top word in the word cloud")
+	_, err = os.Stat(cfg.Render.Paths[1])
+	assert.Nil(t, err, "Expected the bar chart to be drawn")
+}
+
+// Test the HTML report lists the documents counted and the urls which failed with their reason
+func TestStartWorkerPoolHTMLReport(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	dir := t.TempDir()
+	urls := path.Join(dir, "urls.txt")
+	assert.Nil(t, os.WriteFile(urls, []byte("https://example.com/essay-1\nhttps://example.com/broken"), 0644), "Expected no error writing the urls")
+	cfg := config.Get()
+	cfg.DefaultFilePath = urls
+	cfg.HTMLReportPath = path.Join(dir, "report.html")
+	config.Set(cfg)
+	var out bytes.Buffer
+	stdout = &out
+	defer func() { stdout = os.Stdout }()
+	externalsFetchEssay = func(_ context.Context, _, url string) (io.ReadCloser, error) {
+		if path.Base(url) == "broken" {
+			return io.NopCloser(io.MultiReader(strings.NewReader("<p>partial</p>"), iotestErrReader{})), nil
+		}
+		return os.Open(path.Join("testdata", "pages", path.Base(url)+".html"))
+	}
+	defer unMockFetchEssay()
+
+	assert.Nil(t, StartWorkerPool(context.Background()), "Expected no error from StartWorkerPool")
+	assert.Contains(t, out.String(), `"failures": [`, "Expected the failed urls in the result")
+	content, err := os.ReadFile(cfg.HTMLReportPath)
+	assert.Nil(t, err, "Expected the HTML report to be written")
+	report := string(content)
+	assert.Contains(t, report, `<td class="url">https://example.com/broken</td><td>connection reset</td>`, "Expected the failed url and its reason")
+	assert.Contains(t, report, `<h3 class="url">https://example.com/essay-1</h3>`, "Expected the preview of the page counted")
+	assert.Contains(t, report, "htmlReport: "+cfg.HTMLReportPath, "Expected the configuration of the run")
+}
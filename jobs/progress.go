@@ -0,0 +1,124 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+)
+
+// refresh is how often the progress is redrawn on a terminal
+const refresh = 200 * time.Millisecond
+
+// isTerminal reports whether stderr is a terminal the progress can be redrawn on
+var isTerminal = func() bool {
+	info, err := os.Stderr.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progress counts what the scrappers and the tokenizers of a run have done
+type progress struct {
+	started time.Time
+	// total is the number of urls to count, done the ones counted and failed the others
+	total, done, failed atomic.Int64
+	// bytes is the number of bytes of the pages read, tokens the number of words in them
+	bytes, tokens atomic.Int64
+}
+
+func newProgress() *progress {
+	return &progress{started: now()}
+}
+
+// String describes the progress, estimating the time left from the rate of the
+// urls done since the start
+func (p *progress) String() string {
+	total, done, failed := p.total.Load(), p.done.Load(), p.failed.Load()
+	finished := done + failed
+	rate := 0.0
+	if elapsed := now().Sub(p.started).Seconds(); elapsed > 0 {
+		rate = float64(finished) / elapsed
+	}
+	eta := "unknown"
+	if finished > 0 && total >= finished {
+		eta = time.Duration(float64(total-finished) / rate * float64(time.Second)).Round(time.Second).String()
+	}
+	return fmt.Sprintf("%d/%d urls (%d counted, %d failed), %.1f pages/s, %s read, %d tokens, ETA %s",
+		finished, total, done, failed, rate, formatBytes(p.bytes.Load()), p.tokens.Load(), eta)
+}
+
+// formatBytes writes the number of bytes with a decimal unit
+func formatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, prefix := float64(n)/unit, 0
+	for value >= unit && prefix < len("kMGT")-1 {
+		value /= unit
+		prefix++
+	}
+	return fmt.Sprintf("%.1f %cB", value, "kMGT"[prefix])
+}
+
+// reportProgress shows the progress on stderr while the run lasts, when enabled,
+// and returns the function stopping it once the run is done. The progress is
+// redrawn on a terminal and logged every configured interval otherwise.
+func reportProgress(p *progress) func() {
+	cfg := config.Get().Progress
+	if !cfg.Enabled {
+		return func() {}
+	}
+	terminal := isTerminal()
+	interval := time.Duration(cfg.Interval) * time.Second
+	if terminal {
+		interval = refresh
+	}
+	logger := log.New(stderr, "", log.LstdFlags)
+	show := func() {
+		if terminal {
+			fmt.Fprintf(stderr, "\r\033[K%s", p)
+			return
+		}
+		logger.Printf("Progress: %s", p)
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				show()
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+		show()
+		if terminal {
+			fmt.Fprintln(stderr)
+		}
+	}
+}
+
+// countingReader adds the number of bytes read from a body to n
+type countingReader struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (r countingReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.n.Add(int64(n))
+	return n, err
+}
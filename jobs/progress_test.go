@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the progress reports the urls done, the rate and the time left
+func TestProgressString(t *testing.T) {
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return started }
+	defer func() { now = time.Now }()
+	p := newProgress()
+	assert.Equal(t, "0/0 urls (0 counted, 0 failed), 0.0 pages/s, 0 B read, 0 tokens, ETA unknown", p.String(), "Expected no progress")
+
+	now = func() time.Time { return started.Add(2 * time.Second) }
+	p.total.Store(10)
+	p.done.Store(3)
+	p.failed.Store(1)
+	p.bytes.Store(1500000)
+	p.tokens.Store(4200)
+	assert.Equal(t, "4/10 urls (3 counted, 1 failed), 2.0 pages/s, 1.5 MB read, 4200 tokens, ETA 3s", p.String(), "Expected the progress and the time left")
+}
+
+// Test the progress is redrawn on a terminal and logged otherwise, and only when enabled
+func TestReportProgress(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	defer func() { _ = config.InitConfig(devConfigFilePath) }()
+	var out bytes.Buffer
+	stderr = &out
+	defer func() { stderr = os.Stderr }()
+	defer func(terminal func() bool) { isTerminal = terminal }(isTerminal)
+
+	reportProgress(newProgress())()
+	assert.Empty(t, out.String(), "Expected no progress when disabled")
+
+	config.SetProgress(true)
+	isTerminal = func() bool { return false }
+	reportProgress(newProgress())()
+	assert.Contains(t, out.String(), " Progress: 0/0 urls", "Expected the progress to be logged")
+	assert.True(t, strings.HasSuffix(out.String(), "ETA unknown\n"), "Expected a log line")
+
+	out.Reset()
+	isTerminal = func() bool { return true }
+	p := newProgress()
+	stop := reportProgress(p)
+	p.total.Add(1)
+	time.Sleep(2 * refresh)
+	p.done.Add(1)
+	stop()
+	assert.True(t, strings.HasPrefix(out.String(), "\r\033[K0/1 urls"), "Expected the progress to be redrawn")
+	assert.Contains(t, out.String(), "\r\033[K1/1 urls (1 counted, 0 failed)", "Expected the last progress to be drawn")
+	assert.True(t, strings.HasSuffix(out.String(), "ETA 0s\n"), "Expected the line to be ended once the run is done")
+}
@@ -0,0 +1,36 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/constants"
+)
+
+// ParseTimeout parses the named timeout of the configuration, a Go duration such
+// as 30s, none when it is empty
+func ParseTimeout(name, timeout string) (time.Duration, error) {
+	if timeout == constants.Empty {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q, expected a duration such as 30s", name, timeout)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("the %s cannot be negative, got %s", name, timeout)
+	}
+	return d, nil
+}
+
+// withTimeout bounds ctx by the named timeout of the configuration, exceeding it
+// being the cause the context is done, and leaves it unbounded when there is none.
+// The timeouts are checked by the commands before running.
+func withTimeout(ctx context.Context, name, timeout string) (context.Context, context.CancelFunc) {
+	d, _ := ParseTimeout(name, timeout)
+	if d == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, d, fmt.Errorf("%s of %s exceeded", name, d))
+}
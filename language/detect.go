@@ -0,0 +1,204 @@
+package language
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Unknown is reported when the language of a text could not be detected
+const Unknown = "und"
+
+const (
+	// profileSize is the number of most frequent n-grams kept in the profile of a text
+	profileSize = 300
+	// trainedProfileSize is the number of n-grams of the embedded profiles, the
+	// distance of an n-gram missing from one
+	trainedProfileSize = 2000
+	// SampleSize is the number of runes of a document used to detect its language
+	SampleSize = 4096
+	// minLetters is the minimum number of letters needed to attempt a detection
+	minLetters = 20
+	maxNgram   = 4
+)
+
+// The profiles hold the most frequent n-grams of every language, one per line from
+// the most frequent one, trained by genprofiles.go on the corpus of corpus/, extracted
+// from the gettext catalogs listed in corpus/SOURCES.txt
+//
+//go:generate go run genprofiles.go
+//go:embed profiles/*.txt
+var profileFS embed.FS
+
+// profiles maps each language written in the latin script to its ranked n-gram profile
+var profiles = loadProfiles()
+
+// scripts which identify a language on their own
+var scripts = []struct {
+	table    *unicode.RangeTable
+	language string
+}{
+	{unicode.Hangul, "ko"},
+	{unicode.Thai, "th"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+}
+
+func loadProfiles() map[string]map[string]int {
+	entries, err := profileFS.ReadDir("profiles")
+	if err != nil {
+		panic(err)
+	}
+	result := make(map[string]map[string]int, len(entries))
+	for _, entry := range entries {
+		content, err := profileFS.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		lang := strings.TrimSuffix(entry.Name(), ".txt")
+		ranks := make(map[string]int, trainedProfileSize)
+		for i, gram := range strings.Fields(string(content)) {
+			ranks[gram] = i
+		}
+		if len(ranks) == 0 {
+			panic(fmt.Sprintf("the profile of %s is empty", lang))
+		}
+		result[lang] = ranks
+	}
+	return result
+}
+
+// Languages returns the codes of the languages Detect can recognise
+func Languages() []string {
+	languages := []string{"ja", "zh"}
+	for _, s := range scripts {
+		languages = append(languages, s.language)
+	}
+	for lang := range profiles {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Detect returns the ISO 639-1 code of the language of the text, or Unknown.
+// Texts in a script used by a single language are identified by their script,
+// texts in the latin script by comparing their n-gram profile with the embedded ones.
+func Detect(text string) string {
+	runes := []rune(text)
+	if len(runes) > SampleSize {
+		runes = runes[:SampleSize]
+	}
+	sample := string(runes)
+
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range sample {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			counts["ja"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Latin, r):
+			counts["latin"]++
+		default:
+			for _, s := range scripts {
+				if unicode.Is(s.table, r) {
+					counts[s.language]++
+					break
+				}
+			}
+		}
+	}
+	if letters < minLetters {
+		return Unknown
+	}
+
+	// japanese mixes kanji with kana, so any kana in a mostly Han text means japanese
+	if counts["ja"] > 0 && counts["ja"]+counts["zh"] > letters/2 {
+		return "ja"
+	}
+	best, bestCount := "latin", counts["latin"]
+	for lang, count := range counts {
+		if count > bestCount || (count == bestCount && lang < best) {
+			best, bestCount = lang, count
+		}
+	}
+	if best != "latin" {
+		return best
+	}
+	return closestProfile(profile(sample))
+}
+
+// closestProfile returns the language with the smallest out-of-place distance
+func closestProfile(sample map[string]int) string {
+	best, bestDistance := Unknown, -1
+	for lang, p := range profiles {
+		distance := 0
+		for gram, rank := range sample {
+			if r, ok := p[gram]; ok {
+				distance += abs(rank - r)
+			} else {
+				distance += trainedProfileSize
+			}
+		}
+		if bestDistance < 0 || distance < bestDistance || (distance == bestDistance && lang < best) {
+			best, bestDistance = lang, distance
+		}
+	}
+	return best
+}
+
+// profile ranks the most frequent 1 to 4-grams of the words of the text
+func profile(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		padded := []rune("_" + word + "_")
+		for n := 1; n <= maxNgram; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				gram := string(padded[i : i+n])
+				if gram != "_" {
+					counts[gram]++
+				}
+			}
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+
+	ranks := make(map[string]int, len(grams))
+	for i, gram := range grams {
+		ranks[gram] = i
+	}
+	return ranks
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,253 @@
+//go:build ignore
+
+// genprofiles trains the n-gram profiles of the latin script languages on the
+// corpus committed to corpus/<lang>.txt.gz, so that they are reproducible:
+//
+//	go run genprofiles.go
+//
+// The corpus is extracted from the gettext catalogs of a system, English from
+// their source messages and the other languages from their translations. The
+// system and the catalogs it was extracted from are listed in corpus/SOURCES.txt:
+//
+//	go run genprofiles.go -extract /usr/share/locale
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// profileSize and maxNgram match trainedProfileSize and maxNgram of detect.go
+	profileSize = 2000
+	maxNgram    = 4
+)
+
+// locales are the catalog directories of every language
+var locales = map[string][]string{
+	"de": {"de"},
+	"es": {"es"},
+	"fr": {"fr"},
+	"it": {"it"},
+	"nl": {"nl"},
+	"pt": {"pt", "pt_BR"},
+}
+
+// markup matches the format directives, the tags and the entities of the messages,
+// whose letters are not words
+var markup = regexp.MustCompile(`%(\([^)]*\))?[-+#0-9.*]*[a-zA-Z]|\$\{?\w+\}?|\{[^}]*\}|<[^>]*>|&\w+;|\\[a-z]`)
+
+func main() {
+	extract := flag.String("extract", "", "locale directory to extract the corpus from, instead of training the profiles")
+	flag.Parse()
+	if *extract != "" {
+		if err := extractCorpus(*extract); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	languages := []string{"en"}
+	for lang := range locales {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	for _, lang := range languages {
+		if err := train(lang); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// extractCorpus writes the text of the catalogs of every language to the corpus,
+// the catalogs and their messages in sorted order
+func extractCorpus(dir string) error {
+	var english bytes.Buffer
+	sources := []string{
+		"The corpus of the language profiles, extracted by genprofiles.go from the gettext catalogs of " + system() + ".",
+		"The messages of every catalog keep the license of the package it comes from.",
+	}
+	languages := make([]string, 0, len(locales))
+	for lang := range locales {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	for _, lang := range languages {
+		var text bytes.Buffer
+		var names []string
+		for _, locale := range locales[lang] {
+			catalogs, _ := filepath.Glob(filepath.Join(dir, locale, "LC_MESSAGES", "*.mo"))
+			sort.Strings(catalogs)
+			for _, catalog := range catalogs {
+				messages, err := readCatalog(catalog)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "skipping %s: %v\n", catalog, err)
+					continue
+				}
+				names = append(names, locale+"/"+filepath.Base(catalog))
+				sourceMessages := make([]string, 0, len(messages))
+				for source := range messages {
+					sourceMessages = append(sourceMessages, source)
+				}
+				sort.Strings(sourceMessages)
+				for _, source := range sourceMessages {
+					translation := messages[source]
+					// untranslated messages are english
+					if translation == source {
+						continue
+					}
+					text.WriteString(markup.ReplaceAllString(translation, " ") + "\n")
+					if lang == "de" {
+						english.WriteString(markup.ReplaceAllString(source, " ") + "\n")
+					}
+				}
+			}
+		}
+		if text.Len() == 0 {
+			return fmt.Errorf("no catalog of %s under %s", lang, dir)
+		}
+		sources = append(sources, lang+": "+strings.Join(names, " "))
+		if err := writeCorpus(lang, text.Bytes()); err != nil {
+			return err
+		}
+	}
+	sources = append(sources, "en: the source messages of the de catalogs")
+	if err := writeCorpus("en", english.Bytes()); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join("corpus", "SOURCES.txt"), []byte(strings.Join(sources, "\n")+"\n"), 0644)
+}
+
+// system returns the name of the system the catalogs come from
+func system() string {
+	release, _ := os.ReadFile("/etc/os-release")
+	for _, line := range strings.Split(string(release), "\n") {
+		if name, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+			return strings.Trim(name, `"`)
+		}
+	}
+	return "an unknown system"
+}
+
+// writeCorpus compresses the text of the language to corpus/<lang>.txt.gz
+func writeCorpus(lang string, text []byte) error {
+	var buf bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	w.Write(text)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join("corpus", lang+".txt.gz"), buf.Bytes(), 0644)
+}
+
+// train saves the profile of the corpus of the language to profiles/<lang>.txt,
+// failing when the corpus has no text
+func train(lang string) error {
+	f, err := os.Open(filepath.Join("corpus", lang+".txt.gz"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("invalid corpus of %s: %w", lang, err)
+	}
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("invalid corpus of %s: %w", lang, err)
+	}
+	grams := profile(string(text))
+	if len(grams) == 0 {
+		return fmt.Errorf("the corpus of %s has no text", lang)
+	}
+	fmt.Printf("%s: %d bytes of text\n", lang, len(text))
+	return os.WriteFile(filepath.Join("profiles", lang+".txt"), []byte(strings.Join(grams, "\n")+"\n"), 0644)
+}
+
+// readCatalog returns the translation of every message of a .mo file, the forms
+// of the plural messages being joined
+func readCatalog(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 20 {
+		return nil, fmt.Errorf("not a catalog")
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if binary.BigEndian.Uint32(data) == 0x950412de {
+		order = binary.BigEndian
+	} else if order.Uint32(data) != 0x950412de {
+		return nil, fmt.Errorf("not a catalog")
+	}
+	n, sources, translations := order.Uint32(data[8:]), order.Uint32(data[12:]), order.Uint32(data[16:])
+	str := func(table, i uint32) (string, error) {
+		at := uint64(table) + 8*uint64(i)
+		if at+8 > uint64(len(data)) {
+			return "", fmt.Errorf("truncated catalog")
+		}
+		length, offset := uint64(order.Uint32(data[at:])), uint64(order.Uint32(data[at+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", fmt.Errorf("truncated catalog")
+		}
+		return strings.ReplaceAll(string(data[offset:offset+length]), "\x00", "\n"), nil
+	}
+	messages := make(map[string]string, n)
+	for i := uint32(0); i < n; i++ {
+		source, err := str(sources, i)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := str(translations, i)
+		if err != nil {
+			return nil, err
+		}
+		// the empty message is the header of the catalog
+		if source != "" {
+			messages[source] = translation
+		}
+	}
+	return messages, nil
+}
+
+// profile ranks the most frequent 1 to 4-grams of the words of the text like
+// the profile function of detect.go
+func profile(text string) []string {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		padded := []rune("_" + word + "_")
+		for n := 1; n <= maxNgram; n++ {
+			for i := 0; i+n <= len(padded); i++ {
+				if gram := string(padded[i : i+n]); gram != "_" {
+					counts[gram]++
+				}
+			}
+		}
+	}
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+	return grams
+}
@@ -0,0 +1,152 @@
+package language
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test every embedded profile holds the n-grams it was trained with
+func TestProfiles(t *testing.T) {
+	for _, lang := range []string{"de", "en", "es", "fr", "it", "nl", "pt"} {
+		assert.Equal(t, trainedProfileSize, len(profiles[lang]), "Expected the trained profile of %s", lang)
+	}
+}
+
+// Test Detect with texts written in the latin script
+func TestDetectLatinLanguages(t *testing.T) {
+	texts := map[string]string{
+		"en": "Scientists say the new telescope will help them understand how the first galaxies were formed after the big bang.",
+		"fr": "Les scientifiques affirment que le nouveau télescope les aidera à comprendre comment les premières galaxies se sont formées.",
+		"de": "Die Wissenschaftler sagen, dass das neue Teleskop ihnen helfen wird zu verstehen, wie die ersten Galaxien entstanden sind.",
+		"es": "Los científicos dicen que el nuevo telescopio les ayudará a entender cómo se formaron las primeras galaxias después del big bang.",
+		"it": "Gli scienziati dicono che il nuovo telescopio li aiuterà a capire come si sono formate le prime galassie dopo il big bang.",
+		"pt": "Os cientistas dizem que o novo telescópio vai ajudá-los a entender como as primeiras galáxias se formaram depois do big bang.",
+		"nl": "Wetenschappers zeggen dat de nieuwe telescoop hen zal helpen begrijpen hoe de eerste sterrenstelsels na de oerknal zijn ontstaan.",
+	}
+	for expected, text := range texts {
+		assert.Equal(t, expected, Detect(text), "Language detected incorrectly for %q", text)
+	}
+}
+
+// Test Detect tells close languages apart in everyday prose
+func TestDetectCloseLanguages(t *testing.T) {
+	texts := map[string][]string{
+		"es": {"Mi abuela vivía en un pueblo pequeño cerca de la costa, y todos los veranos íbamos a visitarla con mis hermanos.", "El gobierno anunció ayer nuevas medidas para reducir el precio de la vivienda en las grandes ciudades."},
+		"pt": {"A minha avó morava numa aldeia pequena perto da costa, e todos os verões íamos visitá-la com os meus irmãos.", "O governo anunciou ontem novas medidas para reduzir o preço da habitação nas grandes cidades."},
+		"it": {"Mia nonna viveva in un piccolo paese vicino alla costa, e ogni estate andavamo a trovarla con i miei fratelli.", "Il governo ha annunciato ieri nuove misure per ridurre il prezzo delle case nelle grandi città."},
+		"fr": {"Ma grand-mère habitait un petit village près de la côte, et chaque été nous allions lui rendre visite avec mes frères.", "Le gouvernement a annoncé hier de nouvelles mesures pour réduire le prix du logement dans les grandes villes."},
+		"de": {"Meine Großmutter wohnte in einem kleinen Dorf an der Küste, und jeden Sommer besuchten wir sie mit meinen Brüdern.", "Die Regierung hat gestern neue Maßnahmen angekündigt, um die Wohnungspreise in den großen Städten zu senken."},
+		"nl": {"Mijn grootmoeder woonde in een klein dorp aan de kust, en elke zomer gingen we haar met mijn broers bezoeken.", "De regering heeft gisteren nieuwe maatregelen aangekondigd om de huizenprijzen in de grote steden te verlagen."},
+		"en": {"My grandmother lived in a small village near the coast, and every summer we went to visit her with my brothers.", "The government announced new measures yesterday to bring down the price of housing in the big cities."},
+	}
+	for expected, sentences := range texts {
+		for _, text := range sentences {
+			assert.Equal(t, expected, Detect(text), "Language detected incorrectly for %q", text)
+		}
+	}
+}
+
+// Test Detect with texts identified by their script
+func TestDetectScripts(t *testing.T) {
+	assert.Equal(t, "ja", Detect("東京は日本の首都であり、世界で最も人口の多い都市の一つです。多くの人がここで働いています。"), "Expected japanese")
+	assert.Equal(t, "zh", Detect("北京是中国的首都，也是世界上人口最多的城市之一。很多人在这里工作和生活。"), "Expected chinese")
+	assert.Equal(t, "th", Detect("กรุงเทพมหานครเป็นเมืองหลวงของประเทศไทยและเป็นเมืองที่มีประชากรมากที่สุด"), "Expected thai")
+	assert.Equal(t, "ru", Detect("Москва является столицей России и одним из крупнейших городов мира."), "Expected russian")
+}
+
+// Test Detect with a text too short to be detected
+func TestDetectUnknown(t *testing.T) {
+	assert.Equal(t, Unknown, Detect("ok 42"), "Expected unknown language for a short text")
+}
+
+// Test For to ensure pipelines come with their stop words and stemmer
+func TestForPipeline(t *testing.T) {
+	p := For("en")
+	assert.True(t, p.IsStopWord("the"), "Expected 'the' to be an english stop word")
+	assert.False(t, p.IsStopWord("essay"), "Expected 'essay' not to be a stop word")
+	assert.NotNil(t, p.Stem, "Expected an english stemmer")
+	assert.Same(t, p, For("en"), "Expected pipelines to be cached")
+	assert.Empty(t, p.Elisions, "Expected no english elisions")
+	assert.Contains(t, For("fr").Elisions, "qu'", "Expected the french elisions")
+
+	unknown := For(Unknown)
+	assert.Empty(t, unknown.StopWords, "Expected no stop words for an unknown language")
+	assert.Nil(t, unknown.Stem, "Expected no stemmer for an unknown language")
+}
+
+// Test the english stemmer with common inflections
+func TestStemEnglish(t *testing.T) {
+	cases := map[string]string{
+		"essays":  "essay",
+		"stories": "story",
+		"boxes":   "box",
+		"running": "run",
+		"stopped": "stop",
+		"walked":  "walk",
+		"quickly": "quick",
+		"speed":   "speed",
+		"class":   "class",
+		"thing":   "thing",
+	}
+	for word, expected := range cases {
+		assert.Equal(t, expected, stemEnglish(word), "Stem mismatch for %q", word)
+	}
+}
+
+// Test Segment with the embedded chinese dictionary
+func TestSegmentChinese(t *testing.T) {
+	words := For("zh").Segment("北京是中国的首都也是世界上人口最多的城市之一")
+	expected := []string{"北京", "是", "中国", "的", "首都", "也", "是", "世界上", "人口", "最多", "的", "城市", "之一"}
+	assert.Equal(t, expected, words, "Chinese text segmented incorrectly")
+}
+
+// Test Segment with japanese mixing kanji and kana
+func TestSegmentJapanese(t *testing.T) {
+	words := For("ja").Segment("東京は日本の首都です")
+	expected := []string{"東京", "は", "日本", "の", "首都", "です"}
+	assert.Equal(t, expected, words, "Japanese text segmented incorrectly")
+}
+
+// Test Segment with thai and its combining vowel marks
+func TestSegmentThai(t *testing.T) {
+	words := For("th").Segment("กรุงเทพมหานครเป็นเมืองหลวงของประเทศไทย")
+	expected := []string{"กรุงเทพมหานคร", "เป็น", "เมืองหลวง", "ของ", "ประเทศไทย"}
+	assert.Equal(t, expected, words, "Thai text segmented incorrectly")
+}
+
+// Test Segment splits the characters missing from the dictionary instead of keeping them as a word
+func TestSegmentUnknownCharacters(t *testing.T) {
+	s := NewSegmenter([]string{"北京", "大学"})
+	assert.Equal(t, []string{"你", "好", "北京", "大学"}, s.Segment("你好北京大学"), "Expected unknown characters to be split")
+	assert.Equal(t, []string{"コンピューター", "を", "使", "う"}, s.Segment("コンピューターを使う"), "Expected a katakana run to be a word")
+	assert.Equal(t, []string{"เมือง", "กิน", "ข้าว", "ประ", "เทศ", "ใกล้", "เปรียบ", "อา", "หาร"}, s.Segment("เมืองกินข้าวประเทศใกล้เปรียบอาหาร"), "Expected thai to be split into syllables")
+}
+
+// Test the embedded dictionaries are samples, the words of prose missing from them
+// being split into characters, katakana words and syllables
+func TestSegmentSampleDictionaries(t *testing.T) {
+	assert.Equal(t, []string{"我", "昨天", "在", "图", "书", "馆", "借", "了", "三", "本", "关于", "量", "子", "物", "理", "的", "书"},
+		For("zh").Segment("我昨天在图书馆借了三本关于量子物理的书"), "Expected the chinese words missing from the sample to be split into characters")
+	assert.Equal(t, []string{"昨日", "は", "コンピューター", "で", "量", "子", "力", "学", "の", "論", "文", "を", "読", "み", "ました"},
+		For("ja").Segment("昨日はコンピューターで量子力学の論文を読みました"), "Expected the japanese words missing from the sample to be split into characters")
+	assert.Equal(t, []string{"เมื่อวาน", "นี้", "ฉัน", "ไป", "กิน", "ข้าว", "กับ", "เพื่อน", "ที่", "ร้าน", "อา", "หาร", "ใกล้", "มหาวิทยาลัย"},
+		For("th").Segment("เมื่อวานนี้ฉันไปกินข้าวกับเพื่อนที่ร้านอาหารใกล้มหาวิทยาลัย"), "Expected the thai words missing from the sample to be split into syllables")
+}
+
+// Test LoadDictionary replaces the embedded dictionary
+func TestLoadDictionary(t *testing.T) {
+	path := t.TempDir() + "/zh.txt"
+	assert.Nil(t, os.WriteFile(path, []byte("# custom dictionary\n你好 120\n北京\n"), 0644), "Expected no error writing the dictionary")
+	assert.Nil(t, LoadDictionary("zh", path), "Expected no error loading the dictionary")
+	defer func() {
+		pipelineMux.Lock()
+		delete(segmenters, "zh")
+		delete(pipelines, "zh")
+		pipelineMux.Unlock()
+	}()
+
+	assert.Equal(t, []string{"你好", "北京", "大", "学"}, For("zh").Segment("你好北京大学"), "Expected the loaded dictionary to be used")
+	assert.NotNil(t, LoadDictionary("zh", "./missing.txt"), "Expected an error for a missing dictionary")
+}
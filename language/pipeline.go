@@ -0,0 +1,89 @@
+package language
+
+import (
+	"embed"
+	"path"
+	"strings"
+	"sync"
+)
+
+//go:embed stopwords/*.txt
+var stopWordsFS embed.FS
+
+// Pipeline holds the language specific processing of the words of a document
+type Pipeline struct {
+	Language string
+	// Segment splits a run of text written without spaces into words, nil when not needed
+	Segment   func(text string) []string
+	StopWords map[string]bool
+	// Stem reduces a word to its stem, nil means the words are kept as they are
+	Stem func(word string) string
+	// MinWordLength overrides the configured minimum word length when not zero
+	MinWordLength int
+	// Elisions are the elided words split from the word they precede, such as "l'"
+	Elisions []string
+}
+
+var (
+	pipelines   = make(map[string]*Pipeline)
+	pipelineMux sync.Mutex
+)
+
+var stemmers = map[string]func(string) string{
+	"en": stemEnglish,
+	"fr": stemFrench,
+	"de": stemGerman,
+	"es": stemSpanish,
+	"it": stemItalian,
+	"pt": stemPortuguese,
+	"nl": stemDutch,
+}
+
+// elisions of the languages which elide articles and pronouns before a vowel
+var elisions = map[string][]string{
+	"fr": {"c'", "d'", "j'", "l'", "m'", "n'", "s'", "t'", "qu'", "jusqu'", "lorsqu'", "puisqu'", "quoiqu'"},
+	"it": {"c'", "d'", "l'", "un'", "all'", "dall'", "dell'", "nell'", "sull'", "coll'", "quest'", "quell'"},
+}
+
+// For returns the pipeline of the language. Languages without stop words or
+// stemmer get a pipeline which keeps every word.
+func For(language string) *Pipeline {
+	pipelineMux.Lock()
+	defer pipelineMux.Unlock()
+	if p, ok := pipelines[language]; ok {
+		return p
+	}
+	p := &Pipeline{
+		Language:  language,
+		StopWords: loadStopWords(language),
+		Stem:      stemmers[language],
+		Elisions:  elisions[language],
+	}
+	if segmentedLanguages[language] {
+		if s := segmenterFor(language); s != nil {
+			p.Segment = s.Segment
+			// words of these scripts are often a single character long
+			p.MinWordLength = 1
+		}
+	}
+	pipelines[language] = p
+	return p
+}
+
+// IsStopWord reports whether the word is a stop word of the pipeline language
+func (p *Pipeline) IsStopWord(word string) bool {
+	return p.StopWords[word]
+}
+
+func loadStopWords(language string) map[string]bool {
+	stopWords := make(map[string]bool)
+	content, err := stopWordsFS.ReadFile(path.Join("stopwords", language+".txt"))
+	if err != nil {
+		// not every language comes with a stop words list
+		return stopWords
+	}
+	for _, word := range strings.Fields(string(content)) {
+		stopWords[word] = true
+	}
+	return stopWords
+}
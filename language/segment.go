@@ -0,0 +1,267 @@
+package language
+
+import (
+	"bufio"
+	"embed"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed dictionaries/*.txt
+var dictionaryFS embed.FS
+
+// segmentedLanguages are written without spaces between words
+var segmentedLanguages = map[string]bool{"zh": true, "ja": true, "th": true}
+
+// Segmenter splits text written without spaces between words into the words of a dictionary
+type Segmenter struct {
+	words  map[string]bool
+	maxLen int
+}
+
+// NewSegmenter creates a segmenter from the words of a dictionary
+func NewSegmenter(words []string) *Segmenter {
+	s := &Segmenter{words: make(map[string]bool, len(words))}
+	for _, word := range words {
+		s.words[word] = true
+		if n := utf8.RuneCountInString(word); n > s.maxLen {
+			s.maxLen = n
+		}
+	}
+	return s
+}
+
+// ReadDictionary reads a dictionary with one word per line. Anything after the
+// first field of a line, e.g. a frequency, is ignored, as well as lines starting with '#'.
+func ReadDictionary(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		words = append(words, fields[0])
+	}
+	return words, scanner.Err()
+}
+
+// LoadDictionary replaces the embedded dictionary of the language with the one at path
+func LoadDictionary(language, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	words, err := ReadDictionary(f)
+	if err != nil {
+		return err
+	}
+
+	pipelineMux.Lock()
+	defer pipelineMux.Unlock()
+	segmentedLanguages[language] = true
+	segmenters[language] = NewSegmenter(words)
+	// the pipeline is built again with the new dictionary
+	delete(pipelines, language)
+	return nil
+}
+
+// segmenters holds the dictionaries loaded from disk, guarded by pipelineMux
+var segmenters = make(map[string]*Segmenter)
+
+// segmenterFor returns the segmenter of the language, the caller must hold pipelineMux
+func segmenterFor(language string) *Segmenter {
+	if s, ok := segmenters[language]; ok {
+		return s
+	}
+	content, err := dictionaryFS.ReadFile(path.Join("dictionaries", language+".txt"))
+	if err != nil {
+		return nil
+	}
+	words, err := ReadDictionary(strings.NewReader(string(content)))
+	if err != nil {
+		return nil
+	}
+	segmenters[language] = NewSegmenter(words)
+	return segmenters[language]
+}
+
+// step is the best way found to segment the text up to a position
+type step struct {
+	// unknown is the number of characters missing from the dictionary, -1 if unreachable
+	unknown int
+	words   int
+	// prev is the start of the last word, which is known when found in the dictionary
+	prev  int
+	known bool
+}
+
+// better reports whether the step leaves fewer unknown characters, then uses fewer words
+func (s step) better(other step) bool {
+	if other.unknown < 0 {
+		return true
+	}
+	if s.unknown != other.unknown {
+		return s.unknown < other.unknown
+	}
+	return s.words < other.words
+}
+
+// Segment splits the text into the sequence of words which leaves the fewest
+// characters outside of the dictionary, and then uses the fewest words.
+// Consecutive characters not found in the dictionary are split by splitUnknown.
+func (s *Segmenter) Segment(text string) []string {
+	runes := []rune(text)
+	n := len(runes)
+	best := make([]step, n+1)
+	for i := 1; i <= n; i++ {
+		best[i].unknown = -1
+	}
+
+	for i := 0; i < n; i++ {
+		if best[i].unknown < 0 {
+			continue
+		}
+		for l := 1; l <= s.maxLen && i+l <= n; l++ {
+			if !s.words[string(runes[i:i+l])] {
+				continue
+			}
+			candidate := step{unknown: best[i].unknown, words: best[i].words + 1, prev: i, known: true}
+			if candidate.better(best[i+l]) {
+				best[i+l] = candidate
+			}
+		}
+		// a single character missing from the dictionary
+		candidate := step{unknown: best[i].unknown + 1, words: best[i].words + 1, prev: i}
+		if candidate.better(best[i+1]) {
+			best[i+1] = candidate
+		}
+	}
+
+	// walk back the best path, merging the consecutive unknown characters
+	var words []string
+	end := n
+	for end > 0 {
+		start := best[end].prev
+		if !best[end].known {
+			for start > 0 && !best[start].known {
+				start = best[start].prev
+			}
+		}
+		if best[end].known {
+			words = append(words, string(runes[start:end]))
+		} else {
+			unknown := splitUnknown(runes[start:end])
+			for i := len(unknown) - 1; i >= 0; i-- {
+				words = append(words, unknown[i])
+			}
+		}
+		end = start
+	}
+	for i, j := 0, len(words)-1; i < j; i, j = i+1, j-1 {
+		words[i], words[j] = words[j], words[i]
+	}
+	return words
+}
+
+// splitUnknown splits characters missing from the dictionary into words: a run of
+// katakana is a word, loanwords being written in katakana, thai is split into its
+// syllables and the other scripts into single characters, which are mostly words
+// on their own. A character keeps the combining marks following it.
+func splitUnknown(runes []rune) []string {
+	var words []string
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && !startsWord(runes, start, i) {
+			continue
+		}
+		words = append(words, string(runes[start:i]))
+		start = i
+	}
+	return words
+}
+
+// startsWord reports whether the rune at i starts a new word after the one started at start
+func startsWord(runes []rune, start, i int) bool {
+	r, prev := runes[i], runes[i-1]
+	switch {
+	case unicode.Is(unicode.Mn, r):
+		return false
+	case isKatakana(r) && isKatakana(prev):
+		return false
+	case unicode.Is(unicode.Thai, r) && unicode.Is(unicode.Thai, prev):
+		return startsThaiSyllable(runes, start, i)
+	}
+	return true
+}
+
+// startsThaiSyllable reports whether the rune at i starts a new syllable after the
+// one started at start. A syllable is a consonant, or a cluster of a consonant and
+// ร, ล or ว, with its vowels and tone marks, and a final consonant.
+func startsThaiSyllable(runes []rune, start, i int) bool {
+	r := runes[i]
+	switch {
+	case isThaiFollowingVowel(r) || isThaiLeadingVowel(runes[i-1]):
+		return false
+	case isThaiLeadingVowel(r):
+		return true
+	}
+	consonants, leading, vowel := 0, false, false
+	for _, c := range runes[start:i] {
+		switch {
+		case isThaiLeadingVowel(c):
+			leading = true
+		case isThaiFollowingVowel(c) || isThaiVowelMark(c):
+			vowel = true
+		case !unicode.Is(unicode.Mn, c):
+			consonants++
+		}
+	}
+	if !vowel && consonants == 1 && isThaiClusterConsonant(r) {
+		return false
+	}
+	if !vowel && !leading {
+		return true
+	}
+	// a consonant followed by its vowel or tone mark starts the next syllable,
+	// the others end the syllable
+	next := func(j int) bool {
+		return j < len(runes) && (isThaiFollowingVowel(runes[j]) || isThaiVowelMark(runes[j]) || isThaiToneMark(runes[j]))
+	}
+	return next(i+1) || (i+1 < len(runes) && isThaiClusterConsonant(runes[i+1]) && next(i+2))
+}
+
+func isKatakana(r rune) bool {
+	return unicode.Is(unicode.Katakana, r) || r == 'ー'
+}
+
+// isThaiLeadingVowel reports whether the vowel is written before the consonant it follows
+func isThaiLeadingVowel(r rune) bool {
+	return r >= 'เ' && r <= 'ไ'
+}
+
+// isThaiFollowingVowel reports whether the rune is a vowel written after its
+// consonant which is not a combining mark
+func isThaiFollowingVowel(r rune) bool {
+	return r == 'ะ' || r == 'า' || r == 'ำ' || r == 'ๅ'
+}
+
+// isThaiVowelMark reports whether the rune is a vowel combining with its consonant
+func isThaiVowelMark(r rune) bool {
+	return r == '\u0e31' || (r >= '\u0e34' && r <= '\u0e3a') || r == '\u0e47'
+}
+
+// isThaiToneMark reports whether the rune is a tone mark
+func isThaiToneMark(r rune) bool {
+	return r >= '\u0e48' && r <= '\u0e4b'
+}
+
+// isThaiClusterConsonant reports whether the consonant forms a cluster with the one before it
+func isThaiClusterConsonant(r rune) bool {
+	return r == 'ร' || r == 'ล' || r == 'ว'
+}
@@ -0,0 +1,129 @@
+package language
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The stemmers below are light stemmers: they mostly remove inflectional
+// suffixes (plurals, verb endings, gender) so that the counted stems stay
+// readable, without the aggressive derivational rules of a full Porter stemmer.
+
+// stripSuffix removes the first matching suffix, provided the remaining stem
+// keeps at least minStem runes
+func stripSuffix(word string, minStem int, suffixes ...string) (string, bool) {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(word, suffix) && utf8.RuneCountInString(word)-utf8.RuneCountInString(suffix) >= minStem {
+			return strings.TrimSuffix(word, suffix), true
+		}
+	}
+	return word, false
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiouy", b) >= 0
+}
+
+func hasVowel(s string) bool {
+	return strings.IndexAny(s, "aeiouy") >= 0
+}
+
+func stemEnglish(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "sses"), strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "ches"), strings.HasSuffix(word, "xes"):
+		word = strings.TrimSuffix(word, "es")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is") && len(word) > 3:
+		word = strings.TrimSuffix(word, "s")
+	}
+
+	for _, suffix := range []string{"ing", "ed"} {
+		stem := strings.TrimSuffix(word, suffix)
+		if stem == word || len(stem) < 3 || !hasVowel(stem) || strings.HasSuffix(word, "eed") {
+			continue
+		}
+		// running -> run, stopped -> stop
+		n := len(stem)
+		if stem[n-1] == stem[n-2] && !isVowel(stem[n-1]) && strings.IndexByte("lsz", stem[n-1]) < 0 {
+			stem = stem[:n-1]
+		}
+		return stem
+	}
+
+	if stem, ok := stripSuffix(word, 4, "ly"); ok {
+		return stem
+	}
+	return word
+}
+
+func stemFrench(word string) string {
+	word, _ = stripSuffix(word, 3, "aux")
+	if stem, ok := stripSuffix(word, 3, "s", "x"); ok {
+		word = stem
+	}
+	if stem, ok := stripSuffix(word, 4, "ement", "ment"); ok {
+		return stem
+	}
+	if stem, ok := stripSuffix(word, 3, "é", "ée", "er", "e"); ok {
+		return stem
+	}
+	return word
+}
+
+func stemGerman(word string) string {
+	word = strings.NewReplacer("ä", "a", "ö", "o", "ü", "u").Replace(word)
+	if stem, ok := stripSuffix(word, 4, "ern", "em", "en", "er", "es"); ok {
+		return stem
+	}
+	if stem, ok := stripSuffix(word, 3, "e", "s", "n"); ok {
+		return stem
+	}
+	return word
+}
+
+func stemSpanish(word string) string {
+	if stem, ok := stripSuffix(word, 4, "mente"); ok {
+		return stem
+	}
+	if stem, ok := stripSuffix(word, 3, "ces"); ok {
+		return stem + "z"
+	}
+	if stem, ok := stripSuffix(word, 3, "es", "s"); ok {
+		word = stem
+	}
+	word, _ = stripSuffix(word, 3, "a", "o", "e")
+	return word
+}
+
+func stemItalian(word string) string {
+	if stem, ok := stripSuffix(word, 4, "mente"); ok {
+		return stem
+	}
+	word, _ = stripSuffix(word, 3, "i", "e", "a", "o")
+	return word
+}
+
+func stemPortuguese(word string) string {
+	if stem, ok := stripSuffix(word, 4, "mente"); ok {
+		return stem
+	}
+	if stem, ok := stripSuffix(word, 3, "ões", "ães"); ok {
+		return stem + "ão"
+	}
+	if stem, ok := stripSuffix(word, 3, "es", "s"); ok {
+		word = stem
+	}
+	word, _ = stripSuffix(word, 3, "a", "o", "e")
+	return word
+}
+
+func stemDutch(word string) string {
+	if stem, ok := stripSuffix(word, 3, "heden"); ok {
+		return stem + "heid"
+	}
+	if stem, ok := stripSuffix(word, 3, "en", "s", "e"); ok {
+		return stem
+	}
+	return word
+}
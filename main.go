@@ -2,52 +2,36 @@ package main
 
 import (
 	"context"
-	"flag"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
 
-	"github.com/joshy-joy/essay-word-counter/config"
-	"github.com/joshy-joy/essay-word-counter/constants"
-	"github.com/joshy-joy/essay-word-counter/jobs"
+	"github.com/joshy-joy/essay-word-counter/commands"
 )
 
+// shutdown cancels the context on the first interrupt or termination signal, so
+// that the run stops cleanly with its partial results, and exits on the second one
 func shutdown(cancel context.CancelFunc) {
 	// Capture system interrupt signals for graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	<-c
-	log.Println("Execution terminated")
+	log.Println("Execution terminated, finishing the documents in progress, signal again to exit now")
 	cancel()
-}
-
-func getFlags() {
-	file := flag.String(constants.FileFlagConstantName, config.Get().DefaultFilePath, "Optional: To set file path containing the url")
-	count := flag.Int(constants.TopFlagConstantName, config.Get().ResultLength, "Optional: To set result count")
-	flag.Parse()
-	config.SetFilePath(*file)
-	config.SetTopN(*count)
+	<-c
+	log.Println("Execution killed")
+	os.Exit(commands.ExitFailure)
 }
 
 // Main function with graceful shutdown support
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// Graceful shutdown on interrupt signal
 	go shutdown(cancel)
 
-	// load production configs
-	err := config.InitConfig(constants.ProdConfigFilePath)
-	if err != nil {
-		log.Fatal("error initializing configurations")
-	}
-
-	// get arguments from cmd
-	getFlags()
-
-	err = jobs.StartWorkerPool(ctx)
-	if err != nil {
-		log.Fatal("error running job")
-	}
+	code := commands.Run(ctx, os.Args[1:])
+	cancel()
+	os.Exit(code)
 }
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"io"
+
+	"github.com/joshy-joy/essay-word-counter/utils/textstats"
+)
+
+// Document is the response body of a single essay and its position in the input list.
+// Format is the format of the body, html when it is empty.
+type Document struct {
+	Index  int
+	URL    string
+	Format string
+	Body   io.ReadCloser
+}
+
+// WordCount is a word, its rank in the result starting at 1 and the number of times it appears
+type WordCount struct {
+	Rank  int    `json:"rank"`
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// DocumentStats holds the statistics computed for a single essay
+type DocumentStats struct {
+	URL      string `json:"url"`
+	Language string `json:"language"`
+	textstats.Stats
+}
+
+// Failure is an essay which could not be counted and the reason why
+type Failure struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
+}
+
+// Approximation describes the error bounds of counts computed in approximate mode.
+// Every count exceeds the true count by at most MaxError, always with Space-Saving
+// and with a probability of at least 1 - Delta with the Count-Min Sketch.
+type Approximation struct {
+	Algorithm string  `json:"algorithm"`
+	Epsilon   float64 `json:"epsilon"`
+	Delta     float64 `json:"delta,omitempty"`
+	// Words is the number of words counted, MaxError being Epsilon times Words
+	Words    int `json:"words"`
+	MaxError int `json:"maxError"`
+	// Counters is the number of counters the counts were kept in
+	Counters int `json:"counters"`
+}
+
+// LanguageResult holds the top words of the essays written in a single language
+type LanguageResult struct {
+	Language  string      `json:"language"`
+	Documents int         `json:"documents"`
+	TopWords  []WordCount `json:"topWords"`
+	// Approximation is set when the words were counted in approximate mode
+	Approximation *Approximation `json:"approximation,omitempty"`
+}
+
+// Result is the final output of a run
+type Result struct {
+	TopWords      []WordCount      `json:"topWords"`
+	Approximation *Approximation   `json:"approximation,omitempty"`
+	Languages     []LanguageResult `json:"languages"`
+	Documents     []DocumentStats  `json:"documents"`
+	Failures      []Failure        `json:"failures,omitempty"`
+	// Rejected holds the lines of the url lists which were not counted
+	Rejected []RejectedLine `json:"rejected,omitempty"`
+	// Incomplete is set when the run was stopped before every url was counted,
+	// Pending holding the urls which were not
+	Incomplete bool     `json:"incomplete,omitempty"`
+	Pending    []string `json:"pending,omitempty"`
+}
+
+// VocabularyWord is a word of the vocabulary, its number of occurrences and the
+// number of documents it appears in
+type VocabularyWord struct {
+	ID        int
+	Word      string
+	Count     int
+	Documents int
+}
+
+// VocabularyDocument is a document whose words are part of the vocabulary
+type VocabularyDocument struct {
+	ID          int
+	URL         string
+	Language    string
+	Words       int
+	UniqueWords int
+}
+
+// Occurrence is the number of times a word appears in a document
+type Occurrence struct {
+	DocumentID int
+	WordID     int
+	Count      int
+}
+
+// Vocabulary is the complete frequency table of a run
+type Vocabulary struct {
+	Words       []VocabularyWord
+	Documents   []VocabularyDocument
+	Occurrences []Occurrence
+}
+
+// TermChange is a word of two compared corpora with its rank and count in each
+// one, both 0 when it does not appear in it, and its keyness
+type TermChange struct {
+	Word        string `json:"word"`
+	RankBefore  int    `json:"rankBefore"`
+	RankAfter   int    `json:"rankAfter"`
+	CountBefore int    `json:"countBefore"`
+	CountAfter  int    `json:"countAfter"`
+	// LogRatio is the binary logarithm of the relative frequency after over the
+	// one before, positive for a word used more often after
+	LogRatio float64 `json:"logRatio"`
+	// ChiSquared measures how unlikely the difference of frequencies is by chance
+	ChiSquared float64 `json:"chiSquared"`
+}
+
+// Comparison holds the differences between the word frequencies of two corpora
+type Comparison struct {
+	// WordsBefore and WordsAfter are the number of words counted in each corpus
+	WordsBefore int          `json:"wordsBefore"`
+	WordsAfter  int          `json:"wordsAfter"`
+	Rising      []TermChange `json:"rising"`
+	Falling     []TermChange `json:"falling"`
+	New         []TermChange `json:"new"`
+	Disappeared []TermChange `json:"disappeared"`
+	// Keywords are the words whose frequency changed the most significantly
+	Keywords []TermChange `json:"keywords"`
+}
+
+// RejectedLine is a line of a url list which does not hold a url to count
+type RejectedLine struct {
+	// Source is the file, url or stdin the list was read from
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,100 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils"
+)
+
+// change is a list of terms of a comparison
+type change struct {
+	kind  string
+	title string
+	terms []models.TermChange
+}
+
+// changes lists the terms of the comparison by kind of change
+func changes(c models.Comparison) []change {
+	return []change{
+		{"rising", "Rising words", c.Rising},
+		{"falling", "Falling words", c.Falling},
+		{"new", "New words", c.New},
+		{"disappeared", "Disappeared words", c.Disappeared},
+		{"keyword", "Keywords", c.Keywords},
+	}
+}
+
+// termRecord is a line of NDJSON output holding a term of a comparison
+type termRecord struct {
+	Type string `json:"type"`
+	models.TermChange
+}
+
+// WriteComparison writes the comparison to w in the given format. CSV and TSV
+// hold a row per term, with the kind of change in the first column.
+func WriteComparison(w io.Writer, format string, c models.Comparison) error {
+	switch format {
+	case JSON:
+		formatted, err := utils.PrettyPrintJSON(c)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, formatted)
+		return err
+	case NDJSON:
+		encoder := json.NewEncoder(w)
+		for _, ch := range changes(c) {
+			for _, term := range ch.terms {
+				if err := encoder.Encode(termRecord{Type: ch.kind, TermChange: term}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case CSV, TSV:
+		writer := csv.NewWriter(w)
+		if format == TSV {
+			writer.Comma = '\t'
+		}
+		rows := [][]string{append([]string{"change"}, termHeader...)}
+		for _, ch := range changes(c) {
+			for _, term := range ch.terms {
+				rows = append(rows, append([]string{ch.kind}, termRow(term)...))
+			}
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	case Markdown:
+		return writeMarkdown(w, comparisonSections(c))
+	case Table:
+		return writeTable(w, comparisonSections(c))
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+var termHeader = []string{"word", "rankBefore", "rankAfter", "countBefore", "countAfter", "logRatio", "chiSquared"}
+
+func termRow(t models.TermChange) []string {
+	return []string{t.Word, strconv.Itoa(t.RankBefore), strconv.Itoa(t.RankAfter), strconv.Itoa(t.CountBefore),
+		strconv.Itoa(t.CountAfter), formatFloat(t.LogRatio), formatFloat(t.ChiSquared)}
+}
+
+// comparisonSections splits the comparison into the tables of the text formats
+func comparisonSections(c models.Comparison) []section {
+	s := []section{{title: "Words counted", rows: [][]string{{"before", "after"}, {strconv.Itoa(c.WordsBefore), strconv.Itoa(c.WordsAfter)}}}}
+	for _, ch := range changes(c) {
+		rows := [][]string{termHeader}
+		for _, term := range ch.terms {
+			rows = append(rows, termRow(term))
+		}
+		s = append(s, section{title: ch.title, rows: rows})
+	}
+	return s
+}
@@ -0,0 +1,194 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils"
+)
+
+// Output formats
+const (
+	JSON     = "json"
+	NDJSON   = "ndjson"
+	CSV      = "csv"
+	TSV      = "tsv"
+	Markdown = "markdown"
+	Table    = "table"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{JSON, NDJSON, CSV, TSV, Markdown, Table}
+
+// AllLanguages is the language of the top words counted across every language
+const AllLanguages = "all"
+
+// IsFormat reports whether format is supported
+func IsFormat(format string) bool {
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the result to w in the given format. CSV and TSV hold the top
+// words only, the other formats also hold the statistics of every document.
+func Write(w io.Writer, format string, result models.Result) error {
+	switch format {
+	case JSON:
+		formatted, err := utils.PrettyPrintJSON(result)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, formatted)
+		return err
+	case NDJSON:
+		return writeNDJSON(w, result)
+	case CSV:
+		return writeDelimited(w, ',', result)
+	case TSV:
+		return writeDelimited(w, '\t', result)
+	case Markdown:
+		return writeMarkdown(w, sections(result))
+	case Table:
+		return writeTable(w, sections(result))
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// wordRecord is a line of NDJSON output holding a top word
+type wordRecord struct {
+	Type     string `json:"type"`
+	Language string `json:"language"`
+	models.WordCount
+}
+
+// approximationRecord is a line of NDJSON output holding the error bounds of a language
+type approximationRecord struct {
+	Type     string `json:"type"`
+	Language string `json:"language"`
+	*models.Approximation
+}
+
+// documentRecord is a line of NDJSON output holding the statistics of a document
+type documentRecord struct {
+	Type string `json:"type"`
+	models.DocumentStats
+}
+
+// failureRecord is a line of NDJSON output holding a url which could not be counted
+type failureRecord struct {
+	Type string `json:"type"`
+	models.Failure
+}
+
+// rejectedRecord is a line of NDJSON output holding a line of the url lists which was not counted
+type rejectedRecord struct {
+	Type string `json:"type"`
+	models.RejectedLine
+}
+
+// pendingRecord is a line of NDJSON output holding a url which was not counted
+// because the run was stopped
+type pendingRecord struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// writeNDJSON writes a JSON object per line, the top words first, the documents,
+// the failed urls, the rejected lines and the pending urls last
+func writeNDJSON(w io.Writer, result models.Result) error {
+	encoder := json.NewEncoder(w)
+	write := func(language string, words []models.WordCount, approximation *models.Approximation) error {
+		if approximation != nil {
+			if err := encoder.Encode(approximationRecord{Type: "approximation", Language: language, Approximation: approximation}); err != nil {
+				return err
+			}
+		}
+		for _, word := range words {
+			if err := encoder.Encode(wordRecord{Type: "word", Language: language, WordCount: word}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := write(AllLanguages, result.TopWords, result.Approximation); err != nil {
+		return err
+	}
+	for _, language := range result.Languages {
+		if err := write(language.Language, language.TopWords, language.Approximation); err != nil {
+			return err
+		}
+	}
+	for _, document := range result.Documents {
+		if err := encoder.Encode(documentRecord{Type: "document", DocumentStats: document}); err != nil {
+			return err
+		}
+	}
+	for _, failure := range result.Failures {
+		if err := encoder.Encode(failureRecord{Type: "failure", Failure: failure}); err != nil {
+			return err
+		}
+	}
+	for _, rejected := range result.Rejected {
+		if err := encoder.Encode(rejectedRecord{Type: "rejected", RejectedLine: rejected}); err != nil {
+			return err
+		}
+	}
+	for _, url := range result.Pending {
+		if err := encoder.Encode(pendingRecord{Type: "pending", URL: url}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDelimited writes the top words of every language as rows separated by comma
+func writeDelimited(w io.Writer, comma rune, result models.Result) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+	if err := writer.WriteAll(wordRows(result)); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// wordRows returns the header and the rows of the top words, the words of every
+// language first and those of each language next
+func wordRows(result models.Result) [][]string {
+	rows := [][]string{{"language", "rank", "word", "count"}}
+	add := func(language string, words []models.WordCount) {
+		for _, word := range words {
+			rows = append(rows, []string{language, strconv.Itoa(word.Rank), word.Word, strconv.Itoa(word.Count)})
+		}
+	}
+	add(AllLanguages, result.TopWords)
+	for _, language := range result.Languages {
+		add(language.Language, language.TopWords)
+	}
+	return rows
+}
+
+// documentRows returns the header and the rows of the document statistics
+func documentRows(result models.Result) [][]string {
+	rows := [][]string{{"url", "language", "words", "uniqueWords", "sentences", "paragraphs",
+		"avgSentenceLength", "avgParagraphLength", "typeTokenRatio", "fleschKincaidGrade", "gunningFog"}}
+	for _, d := range result.Documents {
+		rows = append(rows, []string{d.URL, d.Language, strconv.Itoa(d.Words), strconv.Itoa(d.UniqueWords),
+			strconv.Itoa(d.Sentences), strconv.Itoa(d.Paragraphs), formatFloat(d.AvgSentenceLength),
+			formatFloat(d.AvgParagraphLength), formatFloat(d.TypeTokenRatio), formatFloat(d.FleschKincaidGrade),
+			formatFloat(d.GunningFog)})
+	}
+	return rows
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
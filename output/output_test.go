@@ -0,0 +1,149 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils/textstats"
+	"github.com/stretchr/testify/assert"
+)
+
+var result = models.Result{
+	TopWords: []models.WordCount{{Rank: 1, Word: "the", Count: 4}, {Rank: 2, Word: "a,b", Count: 2}},
+	Languages: []models.LanguageResult{
+		{Language: "en", Documents: 1, TopWords: []models.WordCount{{Rank: 1, Word: "the", Count: 4}}},
+	},
+	Documents: []models.DocumentStats{
+		{URL: "https://example.com", Language: "en", Stats: textstats.Stats{Words: 6, UniqueWords: 4, Sentences: 2, Paragraphs: 1, AvgSentenceLength: 3, TypeTokenRatio: 0.67}},
+	},
+}
+
+// write formats the test result
+func write(t *testing.T, format string) string {
+	var b bytes.Buffer
+	assert.Nil(t, Write(&b, format, result), "Expected no error writing %s", format)
+	return b.String()
+}
+
+// Test CSV output quotes the cells and lists the words of every language
+func TestWriteCSV(t *testing.T) {
+	expected := "language,rank,word,count\nall,1,the,4\nall,2,\"a,b\",2\nen,1,the,4\n"
+	assert.Equal(t, expected, write(t, CSV), "CSV output mismatch")
+
+	reader := csv.NewReader(strings.NewReader(write(t, TSV)))
+	reader.Comma = '\t'
+	rows, err := reader.ReadAll()
+	assert.Nil(t, err, "Expected valid TSV")
+	assert.Equal(t, []string{"all", "2", "a,b", "2"}, rows[2], "Expected tab separated cells")
+}
+
+// Test NDJSON output writes a JSON object per word and per document
+func TestWriteNDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(write(t, NDJSON)), "\n")
+	assert.Equal(t, 4, len(lines), "Expected 3 words and 1 document")
+	assert.Equal(t, `{"type":"word","language":"all","rank":1,"word":"the","count":4}`, lines[0], "Word record mismatch")
+	assert.True(t, strings.HasPrefix(lines[3], `{"type":"document","url":"https://example.com","language":"en","words":6,`), "Document record mismatch")
+}
+
+// Test Markdown output writes a table per section
+func TestWriteMarkdown(t *testing.T) {
+	out := write(t, Markdown)
+	assert.Contains(t, out, "## Top words\n\n| rank | word | count |\n| --- | --- | --- |\n| 1 | the | 4 |\n", "Top words table mismatch")
+	assert.Contains(t, out, "## Top words in en (1 documents)", "Expected a section per language")
+	assert.Contains(t, out, "| https://example.com | en | 6 | 4 | 2 | 1 | 3 | 0 | 0.67 | 0 | 0 |", "Document row mismatch")
+}
+
+// Test table output aligns the columns
+func TestWriteTable(t *testing.T) {
+	lines := strings.Split(write(t, Table), "\n")
+	assert.Equal(t, "Top words", lines[0], "Expected the section title")
+	assert.Equal(t, "RANK  WORD  COUNT", lines[1], "Expected an upper case header")
+	assert.Equal(t, "1     the   4", lines[2], "Expected aligned columns")
+}
+
+// Test Write with an unknown format
+func TestWriteUnknownFormat(t *testing.T) {
+	assert.NotNil(t, Write(&bytes.Buffer{}, "xml", result), "Expected an error for an unknown format")
+	assert.False(t, IsFormat("xml"), "Expected xml not to be supported")
+	assert.True(t, IsFormat(Markdown), "Expected markdown to be supported")
+}
+
+// Test the urls which could not be counted are listed after the documents
+func TestWriteFailures(t *testing.T) {
+	failed := result
+	failed.Failures = []models.Failure{{URL: "https://example.com/missing", Reason: "non-200 status code 404"}}
+
+	var b bytes.Buffer
+	assert.Nil(t, Write(&b, NDJSON, failed), "Expected no error writing ndjson")
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Equal(t, `{"type":"failure","url":"https://example.com/missing","reason":"non-200 status code 404"}`, lines[len(lines)-1], "Failure record mismatch")
+
+	b.Reset()
+	assert.Nil(t, Write(&b, Markdown, failed), "Expected no error writing markdown")
+	assert.Contains(t, b.String(), "## Failed URLs\n\n| url | reason |\n| --- | --- |\n| https://example.com/missing | non-200 status code 404 |\n", "Failed URLs table mismatch")
+	assert.NotContains(t, write(t, Markdown), "Failed URLs", "Expected no failed URLs section without failures")
+}
+
+// Test the lines of the url lists which were rejected are listed last
+func TestWriteRejected(t *testing.T) {
+	rejected := result
+	rejected.Rejected = []models.RejectedLine{{Source: "urls.txt", Line: 3, Text: "ftp://example.com", Reason: "not an http url"}}
+
+	var b bytes.Buffer
+	assert.Nil(t, Write(&b, NDJSON, rejected), "Expected no error writing ndjson")
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Equal(t, `{"type":"rejected","source":"urls.txt","line":3,"text":"ftp://example.com","reason":"not an http url"}`, lines[len(lines)-1], "Rejected record mismatch")
+
+	b.Reset()
+	assert.Nil(t, Write(&b, Markdown, rejected), "Expected no error writing markdown")
+	assert.Contains(t, b.String(), "## Rejected lines\n\n| source | line | text | reason |\n| --- | --- | --- | --- |\n| urls.txt | 3 | ftp://example.com | not an http url |\n", "Rejected lines table mismatch")
+}
+
+var comparison = models.Comparison{
+	WordsBefore: 85,
+	WordsAfter:  100,
+	Rising:      []models.TermChange{{Word: "sony", RankBefore: 3, RankAfter: 2, CountBefore: 10, CountAfter: 30, LogRatio: 1.3505, ChiSquared: 9.0155}},
+	Falling:     []models.TermChange{},
+	New:         []models.TermChange{{Word: "twitter", RankAfter: 3, CountAfter: 15, LogRatio: 4.6724, ChiSquared: 13.5217}},
+	Disappeared: []models.TermChange{},
+	Keywords:    []models.TermChange{{Word: "twitter", RankAfter: 3, CountAfter: 15, LogRatio: 4.6724, ChiSquared: 13.5217}},
+}
+
+// Test the comparison is written with the kind of change of every term
+func TestWriteComparison(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, WriteComparison(&b, CSV, comparison), "Expected no error writing csv")
+	expected := "change,word,rankBefore,rankAfter,countBefore,countAfter,logRatio,chiSquared\n" +
+		"rising,sony,3,2,10,30,1.3505,9.0155\nnew,twitter,0,3,0,15,4.6724,13.5217\nkeyword,twitter,0,3,0,15,4.6724,13.5217\n"
+	assert.Equal(t, expected, b.String(), "CSV comparison mismatch")
+
+	b.Reset()
+	assert.Nil(t, WriteComparison(&b, NDJSON, comparison), "Expected no error writing ndjson")
+	assert.True(t, strings.HasPrefix(b.String(), `{"type":"rising","word":"sony","rankBefore":3,`), "NDJSON comparison mismatch")
+
+	b.Reset()
+	assert.Nil(t, WriteComparison(&b, Markdown, comparison), "Expected no error writing markdown")
+	assert.Contains(t, b.String(), "## New words\n\n| word | rankBefore | rankAfter | countBefore | countAfter | logRatio | chiSquared |\n| --- | --- | --- | --- | --- | --- | --- |\n| twitter | 0 | 3 | 0 | 15 | 4.6724 | 13.5217 |\n", "Markdown comparison mismatch")
+
+	assert.NotNil(t, WriteComparison(&bytes.Buffer{}, "xml", comparison), "Expected an error for an unknown format")
+}
+
+// Test the urls of a stopped run are written as pending
+func TestWritePending(t *testing.T) {
+	pending := result
+	pending.Incomplete = true
+	pending.Pending = []string{"https://example.com/essay-3"}
+
+	var b bytes.Buffer
+	assert.Nil(t, Write(&b, NDJSON, pending), "Expected no error writing ndjson")
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	assert.Equal(t, `{"type":"pending","url":"https://example.com/essay-3"}`, lines[len(lines)-1], "Pending record mismatch")
+
+	b.Reset()
+	assert.Nil(t, Write(&b, Markdown, pending), "Expected no error writing markdown")
+	assert.Contains(t, b.String(), "## Top words, incomplete with 1 urls not counted\n", "Expected the result to be marked incomplete")
+	assert.Contains(t, b.String(), "## Pending URLs\n\n| url |\n| --- |\n| https://example.com/essay-3 |\n", "Pending urls table mismatch")
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// section is a titled table of the text formats
+type section struct {
+	title string
+	rows  [][]string
+}
+
+// sections splits the result into the tables of the text formats
+func sections(result models.Result) []section {
+	words := func(title string, words []models.WordCount, approximation *models.Approximation) section {
+		if approximation != nil {
+			title += fmt.Sprintf(", approximate counts within %d", approximation.MaxError)
+		}
+		rows := [][]string{{"rank", "word", "count"}}
+		for _, row := range wordRows(models.Result{TopWords: words})[1:] {
+			rows = append(rows, row[1:])
+		}
+		return section{title: title, rows: rows}
+	}
+
+	title := "Top words"
+	if result.Incomplete {
+		title += fmt.Sprintf(", incomplete with %d urls not counted", len(result.Pending))
+	}
+	s := []section{words(title, result.TopWords, result.Approximation)}
+	for _, language := range result.Languages {
+		title := fmt.Sprintf("Top words in %s (%d documents)", language.Language, language.Documents)
+		s = append(s, words(title, language.TopWords, language.Approximation))
+	}
+	s = append(s, section{title: "Documents", rows: documentRows(result)})
+	if len(result.Failures) > 0 {
+		rows := [][]string{{"url", "reason"}}
+		for _, failure := range result.Failures {
+			rows = append(rows, []string{failure.URL, failure.Reason})
+		}
+		s = append(s, section{title: "Failed URLs", rows: rows})
+	}
+	if len(result.Rejected) > 0 {
+		rows := [][]string{{"source", "line", "text", "reason"}}
+		for _, r := range result.Rejected {
+			rows = append(rows, []string{r.Source, strconv.Itoa(r.Line), r.Text, r.Reason})
+		}
+		s = append(s, section{title: "Rejected lines", rows: rows})
+	}
+	if len(result.Pending) > 0 {
+		rows := [][]string{{"url"}}
+		for _, url := range result.Pending {
+			rows = append(rows, []string{url})
+		}
+		s = append(s, section{title: "Pending URLs", rows: rows})
+	}
+	return s
+}
+
+// writeMarkdown writes every section as a markdown table
+func writeMarkdown(w io.Writer, sections []section) error {
+	var b strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", s.title)
+		for j, row := range s.rows {
+			cells := make([]string, len(row))
+			for k, cell := range row {
+				cells[k] = strings.ReplaceAll(cell, "|", `\|`)
+			}
+			fmt.Fprintf(&b, "| %s |\n", strings.Join(cells, " | "))
+			if j == 0 {
+				fmt.Fprintf(&b, "|%s\n", strings.Repeat(" --- |", len(row)))
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTable writes every section as columns aligned with spaces
+func writeTable(w io.Writer, sections []section) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintln(tw, s.title)
+		for j, row := range s.rows {
+			if j == 0 {
+				row = upper(row)
+			}
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+	}
+	return tw.Flush()
+}
+
+func upper(row []string) []string {
+	result := make([]string, len(row))
+	for i, cell := range row {
+		result[i] = strings.ToUpper(cell)
+	}
+	return result
+}
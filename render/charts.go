@@ -0,0 +1,123 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+const (
+	cloudWidth  = 800
+	cloudHeight = 600
+	// the largest word is drawn maxScale times larger than the font, the smallest minScale
+	minScale = 1.5
+	maxScale = 7
+	// padding keeps the words of the cloud apart
+	padding = 3
+
+	barsWidth  = 800
+	barScale   = 1.5
+	barHeight  = 24
+	barGap     = 8
+	barsMargin = 20
+)
+
+// wordCloud places the words from the most frequent one along a spiral starting
+// near the center, skipping the words which do not fit. Their orientation, color
+// and the start of the spiral are drawn from the seed.
+func wordCloud(words []models.WordCount, seed int64) scene {
+	s := scene{width: cloudWidth, height: cloudHeight}
+	if len(words) == 0 {
+		return s
+	}
+	rnd := rand.New(rand.NewSource(seed))
+	maxCount, minCount := float64(words[0].Count), float64(words[len(words)-1].Count)
+
+	var placed []rect
+	for _, word := range words {
+		// font sizes grow with the square root of the counts, so small counts stay readable
+		ratio := 1.0
+		if maxCount > minCount {
+			ratio = math.Sqrt((float64(word.Count) - minCount) / (maxCount - minCount))
+		}
+		scale := minScale + (maxScale-minScale)*ratio
+		// long words are shrunk to fit the width of the cloud
+		scale = math.Min(scale, float64(cloudWidth-2*padding)/float64(cells(word.Word)*glyphWidth))
+		w, h := textSize(word.Word, scale)
+		// words longer than the height of the cloud stay horizontal
+		vertical := rnd.Intn(4) == 0 && w <= cloudHeight-2*padding
+		if vertical {
+			w, h = h, w
+		}
+		c := palette[rnd.Intn(len(palette))]
+		start := rnd.Float64() * 2 * math.Pi
+
+		for t := 0.0; t < 200; t += 0.05 {
+			radius := 4 * t
+			x := cloudWidth/2 + radius*math.Cos(t+start) - w/2
+			y := cloudHeight/2 + radius*math.Sin(t+start)*cloudHeight/cloudWidth - h/2
+			box := rect{x: x, y: y, w: w, h: h}
+			if x < 0 || y < 0 || x+w > cloudWidth || y+h > cloudHeight {
+				continue
+			}
+			if overlaps(box, placed) {
+				continue
+			}
+			placed = append(placed, box)
+			s.labels = append(s.labels, label{text: word.Word, x: x, y: y, w: w, h: h, color: c, vertical: vertical})
+			break
+		}
+	}
+	return s
+}
+
+// overlaps reports whether the box, grown by the padding, overlaps a placed one
+func overlaps(box rect, placed []rect) bool {
+	for _, p := range placed {
+		if box.x-padding < p.x+p.w && p.x < box.x+box.w+padding &&
+			box.y-padding < p.y+p.h && p.y < box.y+box.h+padding {
+			return true
+		}
+	}
+	return false
+}
+
+// bars draws a horizontal bar per word, in the order of the words, with the
+// word on the left and its count on the right of the bar
+func bars(words []models.WordCount) scene {
+	labelWidth, countWidth := 0.0, 0.0
+	for _, word := range words {
+		w, _ := textSize(word.Word, barScale)
+		labelWidth = math.Max(labelWidth, w)
+		w, _ = textSize(fmt.Sprint(word.Count), barScale)
+		countWidth = math.Max(countWidth, w)
+	}
+	s := scene{width: barsWidth, height: 2*barsMargin + len(words)*(barHeight+barGap) - barGap}
+	if len(words) == 0 {
+		s.height = 2 * barsMargin
+		return s
+	}
+
+	left := barsMargin + labelWidth + barGap
+	length := float64(barsWidth) - left - barGap - countWidth - barsMargin
+	maxCount := float64(words[0].Count)
+	for _, word := range words {
+		maxCount = math.Max(maxCount, float64(word.Count))
+	}
+
+	for i, word := range words {
+		y := float64(barsMargin + i*(barHeight+barGap))
+		w := math.Max(1, length*float64(word.Count)/maxCount)
+		s.rects = append(s.rects, rect{x: left, y: y, w: w, h: barHeight, color: palette[0]})
+
+		lw, lh := textSize(word.Word, barScale)
+		top := y + (barHeight-lh)/2
+		s.labels = append(s.labels, label{text: word.Word, x: left - barGap - lw, y: top, w: lw, h: lh, color: foreground})
+		count := fmt.Sprint(word.Count)
+		cw, _ := textSize(count, barScale)
+		s.labels = append(s.labels, label{text: count, x: left + w + barGap, y: top, w: cw, h: lh, color: foreground})
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// writePNG rasterizes the scene. Labels are drawn with a 7x13 bitmap font
+// scaled to their box. The font only covers ASCII, other characters are drawn
+// as a replacement glyph, so SVG suits words in other scripts better.
+func writePNG(w io.Writer, s scene) error {
+	img := image.NewRGBA(image.Rect(0, 0, s.width, s.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for _, r := range s.rects {
+		draw.Draw(img, bounds(r.x, r.y, r.w, r.h), image.NewUniform(r.color), image.Point{}, draw.Over)
+	}
+	for _, l := range s.labels {
+		box := bounds(l.x, l.y, l.w, l.h)
+		draw.DrawMask(img, box, image.NewUniform(l.color), image.Point{}, glyphMask(l, box.Dx(), box.Dy()), image.Point{}, draw.Over)
+	}
+	return png.Encode(w, img)
+}
+
+// bounds rounds a box to pixels
+func bounds(x, y, w, h float64) image.Rectangle {
+	return image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+}
+
+// glyphMask draws the text of the label with the bitmap font and scales it to a
+// mask of the given size with the nearest neighbour
+func glyphMask(l label, width, height int) *image.Alpha {
+	text := image.NewAlpha(image.Rect(0, 0, cells(l.text)*glyphWidth, glyphHeight))
+	d := font.Drawer{Dst: text, Src: image.Opaque, Face: basicfont.Face7x13, Dot: fixed.P(0, glyphAscent)}
+	d.DrawString(l.text)
+
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	tw, th := text.Bounds().Dx(), text.Bounds().Dy()
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var sx, sy int
+			if l.vertical {
+				// a quarter turn counterclockwise: the top of the glyphs faces left
+				sx, sy = (height-1-y)*tw/height, x*th/width
+			} else {
+				sx, sy = x*tw/width, y*th/height
+			}
+			mask.SetAlpha(x, y, text.AlphaAt(sx, sy))
+		}
+	}
+	return mask
+}
@@ -0,0 +1,137 @@
+package render
+
+import (
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// Chart kinds, chosen from the name of the rendered file
+const (
+	WordCloud = "wordcloud"
+	Bars      = "bars"
+)
+
+// Image formats, chosen from the extension of the rendered file
+const (
+	SVG = ".svg"
+	PNG = ".png"
+)
+
+// Glyphs are laid out on the grid of the 7x13 bitmap font used for PNG images,
+// so that both formats draw the same scene
+const (
+	glyphWidth  = 7
+	glyphHeight = 13
+	glyphAscent = 11
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	foreground = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	palette    = []color.RGBA{
+		{0x1f, 0x77, 0xb4, 0xff}, {0xff, 0x7f, 0x0e, 0xff}, {0x2c, 0xa0, 0x2c, 0xff},
+		{0xd6, 0x27, 0x28, 0xff}, {0x94, 0x67, 0xbd, 0xff}, {0x8c, 0x56, 0x4b, 0xff},
+		{0xe3, 0x77, 0xc2, 0xff}, {0x17, 0xbe, 0xcf, 0xff},
+	}
+)
+
+// scene is a chart as rectangles and labels, drawn by the SVG and PNG writers
+type scene struct {
+	width, height int
+	rects         []rect
+	labels        []label
+}
+
+type rect struct {
+	x, y, w, h float64
+	color      color.RGBA
+}
+
+// label is a text filling its box, which is rotated a quarter turn
+// counterclockwise when vertical
+type label struct {
+	text       string
+	x, y, w, h float64
+	color      color.RGBA
+	vertical   bool
+}
+
+// Kind returns the chart kind and image format of the file at path: the name
+// must contain "cloud" or "bar", and the extension be .svg or .png
+func Kind(path string) (string, string, error) {
+	name := strings.ToLower(filepath.Base(path))
+	format := filepath.Ext(name)
+	if format != SVG && format != PNG {
+		return "", "", fmt.Errorf("unknown render extension %q, expected .svg or .png", filepath.Ext(path))
+	}
+	switch {
+	case strings.Contains(name, "cloud"):
+		return WordCloud, format, nil
+	case strings.Contains(name, "bar"):
+		return Bars, format, nil
+	}
+	return "", "", fmt.Errorf("cannot tell the chart of %q, its name must contain \"cloud\" or \"bar\"", filepath.Base(path))
+}
+
+// Write renders the words to path as the chart and in the format given by Kind.
+// The word cloud layout only depends on the words and the seed.
+func Write(path string, words []models.WordCount, seed int64) error {
+	kind, format, err := Kind(path)
+	if err != nil {
+		return err
+	}
+	s := chart(kind, words, seed)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if format == SVG {
+		err = writeSVG(f, s)
+	} else {
+		err = writePNG(f, s)
+	}
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// WriteSVG renders the words to w as an SVG chart of the given kind
+func WriteSVG(w io.Writer, kind string, words []models.WordCount, seed int64) error {
+	return writeSVG(w, chart(kind, words, seed))
+}
+
+// chart lays out the words as a chart of the given kind
+func chart(kind string, words []models.WordCount, seed int64) scene {
+	if kind == WordCloud {
+		return wordCloud(words, seed)
+	}
+	return bars(words)
+}
+
+// cells returns the width of the text in glyphs, wide east asian characters
+// taking two of them
+func cells(text string) int {
+	n := 0
+	for _, r := range text {
+		n++
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			n++
+		}
+	}
+	return n
+}
+
+// textSize returns the size of the box of a text drawn scale times larger than the font
+func textSize(text string, scale float64) (float64, float64) {
+	return float64(cells(text)*glyphWidth) * scale, glyphHeight * scale
+}
@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// topWords returns n words with decreasing counts
+func topWords(n int) []models.WordCount {
+	words := make([]models.WordCount, n)
+	for i := range words {
+		words[i] = models.WordCount{Rank: i + 1, Word: fmt.Sprintf("word%d", i), Count: 500 / (i + 1)}
+	}
+	return words
+}
+
+// Test Kind picks the chart from the file name and the format from its extension
+func TestKind(t *testing.T) {
+	kind, format, err := Kind("out/wordcloud.svg")
+	assert.Nil(t, err, "Expected no error for a word cloud")
+	assert.Equal(t, []string{WordCloud, SVG}, []string{kind, format}, "Expected an SVG word cloud")
+	kind, format, err = Kind("Bars.PNG")
+	assert.Nil(t, err, "Expected no error for a bar chart")
+	assert.Equal(t, []string{Bars, PNG}, []string{kind, format}, "Expected a PNG bar chart")
+
+	_, _, err = Kind("chart.svg")
+	assert.NotNil(t, err, "Expected an error for an unknown chart")
+	_, _, err = Kind("wordcloud.jpg")
+	assert.NotNil(t, err, "Expected an error for an unknown extension")
+}
+
+// Test the word cloud layout only depends on the seed and never overlaps
+func TestWordCloudLayout(t *testing.T) {
+	words := topWords(40)
+	s := wordCloud(words, 7)
+	assert.Equal(t, s, wordCloud(words, 7), "Expected the same layout for the same seed")
+	assert.NotEqual(t, s, wordCloud(words, 8), "Expected another layout for another seed")
+	assert.Equal(t, "word0", s.labels[0].text, "Expected the most frequent word to be placed first")
+	assert.Greater(t, s.labels[0].h, s.labels[len(s.labels)-1].h, "Expected frequent words to be larger")
+
+	for i, a := range s.labels {
+		assert.True(t, a.x >= 0 && a.y >= 0 && a.x+a.w <= cloudWidth && a.y+a.h <= cloudHeight, "Expected %q inside the cloud", a.text)
+		for _, b := range s.labels[i+1:] {
+			assert.False(t, overlaps(rect{x: a.x, y: a.y, w: a.w, h: a.h}, []rect{{x: b.x, y: b.y, w: b.w, h: b.h}}), "Expected %q and %q not to overlap", a.text, b.text)
+		}
+	}
+}
+
+// Test the bar chart draws a bar per word proportional to its count
+func TestBars(t *testing.T) {
+	s := bars(topWords(5))
+	assert.Equal(t, 5, len(s.rects), "Expected a bar per word")
+	assert.Equal(t, 10, len(s.labels), "Expected a word and a count label per bar")
+	assert.InDelta(t, s.rects[0].w/2, s.rects[1].w, 0.01, "Expected the bars to be proportional to the counts")
+	assert.Equal(t, "500", s.labels[1].text, "Expected the count next to the bar")
+}
+
+// Test Write produces identical files for identical input
+func TestWriteDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"wordcloud.svg", "wordcloud.png", "bars.svg", "bars.png"} {
+		first, second := path.Join(dir, "1-"+name), path.Join(dir, "2-"+name)
+		assert.Nil(t, Write(first, topWords(20), 42), "Expected no error rendering %s", name)
+		assert.Nil(t, Write(second, topWords(20), 42), "Expected no error rendering %s", name)
+		a, _ := os.ReadFile(first)
+		b, _ := os.ReadFile(second)
+		assert.NotEmpty(t, a, "Expected %s to be written", name)
+		assert.Equal(t, a, b, "Expected %s to be byte-identical", name)
+	}
+
+	content, _ := os.ReadFile(path.Join(dir, "1-bars.png"))
+	img, err := png.Decode(bytes.NewReader(content))
+	assert.Nil(t, err, "Expected a valid PNG")
+	assert.Equal(t, barsWidth, img.Bounds().Dx(), "Expected the width of the bar chart")
+}
+
+// Test SVG labels are escaped
+func TestWriteSVGEscape(t *testing.T) {
+	var b bytes.Buffer
+	assert.Nil(t, writeSVG(&b, bars([]models.WordCount{{Rank: 1, Word: "<at&t>", Count: 1}})), "Expected no error writing the SVG")
+	assert.Contains(t, b.String(), ">&lt;at&amp;t&gt;</text>", "Expected the word to be escaped")
+}
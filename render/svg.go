@@ -0,0 +1,52 @@
+package render
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// writeSVG writes the scene as an SVG image. Labels are stretched to their box
+// with textLength, so the layout does not depend on the fonts of the viewer.
+func writeSVG(w io.Writer, s scene) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", s.width, s.height, s.width, s.height)
+	fmt.Fprintf(b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hex(background))
+	for _, r := range s.rects {
+		fmt.Fprintf(b, `<rect x="%s" y="%s" width="%s" height="%s" fill="%s"/>`+"\n", num(r.x), num(r.y), num(r.w), num(r.h), hex(r.color))
+	}
+	for _, l := range s.labels {
+		length, size := l.w, l.h
+		// the baseline is at the ascent of the glyphs from the top of the box
+		x, y := l.x, l.y+l.h*glyphAscent/glyphHeight
+		transform := ""
+		if l.vertical {
+			length, size = l.h, l.w
+			x, y = l.x+l.w*glyphAscent/glyphHeight, l.y+l.h
+			transform = fmt.Sprintf(` transform="rotate(-90 %s %s)"`, num(x), num(y))
+		}
+		fmt.Fprintf(b, `<text x="%s" y="%s" font-family="monospace" font-size="%s" textLength="%s" lengthAdjust="spacingAndGlyphs" fill="%s"%s>%s</text>`+"\n",
+			num(x), num(y), num(size), num(length), hex(l.color), transform, escape(l.text))
+	}
+	fmt.Fprintln(b, "</svg>")
+	return b.Flush()
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// num formats a coordinate with at most 2 decimals
+func num(v float64) string {
+	s := strings.TrimRight(fmt.Sprintf("%.2f", v), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"os"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/render"
+)
+
+// Report is a run and its result, written as a single HTML page
+type Report struct {
+	Result models.Result
+	// Previews hold the beginning of the text extracted from every page counted
+	Previews []Preview
+	// URLs is the number of urls of the input
+	URLs     int
+	Started  time.Time
+	Duration time.Duration
+	// Config is the configuration of the run as YAML
+	Config string
+	// Seed is the seed of the word cloud layout
+	Seed int64
+}
+
+// Preview is the beginning of the text extracted from a page
+type Preview struct {
+	URL      string
+	Language string
+	Text     string
+}
+
+//go:embed report.html
+var page string
+
+var reportTemplate = template.Must(template.New("report").Parse(page))
+
+// view is the data of the template
+type view struct {
+	Report
+	// Words is the total number of words of the documents counted
+	Words int
+	// Took is the duration of the run rounded to the millisecond
+	Took time.Duration
+	// Cloud is the word cloud of the top words as an inline SVG image
+	Cloud template.HTML
+}
+
+// Write writes the report to path as a single HTML file with no external
+// resources, so that it can be shared as it is
+func Write(path string, report Report) error {
+	v := view{Report: report, Took: report.Duration.Round(time.Millisecond)}
+	for _, d := range report.Result.Documents {
+		v.Words += d.Words
+	}
+	if len(report.Result.TopWords) > 0 {
+		var cloud bytes.Buffer
+		if err := render.WriteSVG(&cloud, render.WordCloud, report.Result.TopWords, report.Seed); err != nil {
+			return err
+		}
+		// the SVG writer escapes the words
+		v.Cloud = template.HTML(cloud.String())
+	}
+
+	var b bytes.Buffer
+	if err := reportTemplate.Execute(&b, v); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,68 @@
+package report
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/utils/textstats"
+	"github.com/stretchr/testify/assert"
+)
+
+var report = Report{
+	Result: models.Result{
+		TopWords:  []models.WordCount{{Rank: 1, Word: "the", Count: 4}, {Rank: 2, Word: "<b>", Count: 2}},
+		Languages: []models.LanguageResult{{Language: "en", Documents: 1, TopWords: []models.WordCount{{Rank: 1, Word: "the", Count: 4}}}},
+		Documents: []models.DocumentStats{{URL: "https://example.com", Language: "en", Stats: textstats.Stats{Words: 6, TypeTokenRatio: 0.6667}}},
+		Failures:  []models.Failure{{URL: "https://example.com/missing", Reason: "non-200 status code 404"}},
+	},
+	Previews: []Preview{{URL: "https://example.com", Language: "en", Text: "The text <script>alert(1)</script>"}},
+	URLs:     2,
+	Started:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	Duration: 1234567 * time.Microsecond,
+	Config:   "resultLength: 2\n",
+	Seed:     1,
+}
+
+// write writes the test report and returns its content
+func write(t *testing.T, r Report) string {
+	file := path.Join(t.TempDir(), "report.html")
+	assert.Nil(t, Write(file, r), "Expected no error writing the report")
+	content, err := os.ReadFile(file)
+	assert.Nil(t, err, "Expected the report to be written")
+	return string(content)
+}
+
+// Test the report holds every part of the run
+func TestWrite(t *testing.T) {
+	out := write(t, report)
+	assert.Contains(t, out, "<dt>Started</dt><dd>2024-05-01 10:00:00 UTC</dd>", "Expected the start of the run")
+	assert.Contains(t, out, "<dt>Duration</dt><dd>1.235s</dd>", "Expected the duration rounded to the millisecond")
+	assert.Contains(t, out, `<dt>Failed URLs</dt><dd class="failed">1</dd>`, "Expected the number of failed urls")
+	assert.Contains(t, out, `<tr><td class="number">1</td><td>the</td><td class="number">4</td></tr>`, "Expected the top words table")
+	assert.Contains(t, out, `<td class="number">0.67</td>`, "Expected the document statistics")
+	assert.Contains(t, out, `<td class="url">https://example.com/missing</td><td>non-200 status code 404</td>`, "Expected the failed url and its reason")
+	assert.Contains(t, out, "<pre>resultLength: 2\n</pre>", "Expected the configuration")
+	assert.Contains(t, out, `<div class="cloud"><svg `, "Expected the word cloud inline")
+}
+
+// Test the report is self-contained and escapes the content of the pages
+func TestWriteEscape(t *testing.T) {
+	out := write(t, report)
+	assert.Contains(t, out, "The text &lt;script&gt;alert(1)&lt;/script&gt;", "Expected the preview to be escaped")
+	assert.Contains(t, out, "<td>&lt;b&gt;</td>", "Expected the words to be escaped")
+	assert.Equal(t, 0, strings.Count(out, "<script"), "Expected no script")
+	assert.NotContains(t, out, "<link", "Expected no external stylesheet")
+	assert.NotContains(t, out, "src=", "Expected no external resource")
+}
+
+// Test a run counting nothing still produces a report
+func TestWriteEmpty(t *testing.T) {
+	out := write(t, Report{})
+	assert.Contains(t, out, "No word was counted.", "Expected a note instead of the top words")
+	assert.Contains(t, out, "Every URL was counted.", "Expected a note instead of the failed urls")
+	assert.NotContains(t, out, `class="cloud"`, "Expected no word cloud without words")
+}
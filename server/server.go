@@ -0,0 +1,104 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/joshy-joy/essay-word-counter/output"
+)
+
+// CountFunc counts the words of the pages of the urls
+type CountFunc func(ctx context.Context, urls []string) (models.Result, error)
+
+// maxBodyBytes bounds the size of the list of urls of a request
+const maxBodyBytes = 10 << 20
+
+// contentTypes are the content types of the output formats
+var contentTypes = map[string]string{
+	output.JSON:     "application/json",
+	output.NDJSON:   "application/x-ndjson",
+	output.CSV:      "text/csv; charset=utf-8",
+	output.TSV:      "text/tab-separated-values; charset=utf-8",
+	output.Markdown: "text/markdown; charset=utf-8",
+	output.Table:    "text/plain; charset=utf-8",
+}
+
+// Handler returns the routes of the service:
+//
+//	POST /count   counts the urls of the body, one per line, and responds with the
+//	              result in the configured format or the one of the format parameter
+//	GET  /healthz responds with ok
+func Handler(count CountFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /count", func(w http.ResponseWriter, r *http.Request) {
+		format := config.Get().OutputFormat
+		if f := r.URL.Query().Get("format"); f != "" {
+			format = f
+		}
+		if !output.IsFormat(format) {
+			http.Error(w, fmt.Sprintf("unknown output format %q, expected one of %s", format, strings.Join(output.Formats, ", ")), http.StatusBadRequest)
+			return
+		}
+		urls, err := readURLs(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(urls) == 0 {
+			http.Error(w, "no url to count", http.StatusBadRequest)
+			return
+		}
+
+		result, err := count(r.Context(), urls)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypes[format])
+		if err := output.Write(w, format, result); err != nil {
+			log.Printf("Failed to write the result: %v", err)
+		}
+	})
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "ok\n")
+	})
+	return mux
+}
+
+// readURLs returns the non-blank lines of the body
+func readURLs(body io.Reader) ([]string, error) {
+	var urls []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		if url := strings.TrimSpace(scanner.Text()); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	return urls, scanner.Err()
+}
+
+// ListenAndServe serves the handler on addr until the context is canceled, then
+// waits for the requests in progress to complete
+func ListenAndServe(ctx context.Context, addr string, handler http.Handler) error {
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+		done <- srv.Shutdown(context.Background())
+	}()
+
+	log.Printf("Listening on %s", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return <-done
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const devConfigFilePath = "../resources/dev/config.yml"
+
+// count returns the urls it is given as top words
+func count(_ context.Context, urls []string) (models.Result, error) {
+	var result models.Result
+	for i, url := range urls {
+		result.TopWords = append(result.TopWords, models.WordCount{Rank: i + 1, Word: url, Count: 1})
+	}
+	return result, nil
+}
+
+// post sends the body to the count route
+func post(target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	Handler(count).ServeHTTP(w, httptest.NewRequest(http.MethodPost, target, strings.NewReader(body)))
+	return w
+}
+
+// Test the count route counts the urls of the body in the requested format
+func TestCount(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	w := post("/count", "https://example.com/1\n\n  https://example.com/2\r\n")
+	assert.Equal(t, http.StatusOK, w.Code, "Expected the request to succeed")
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"), "Expected the configured format")
+	assert.Contains(t, w.Body.String(), `"word": "https://example.com/2"`, "Expected the blank lines and spaces to be skipped")
+
+	w = post("/count?format=csv", "https://example.com/1")
+	assert.Equal(t, "language,rank,word,count\nall,1,https://example.com/1,1\n", w.Body.String(), "Expected the requested format")
+}
+
+// Test the count route rejects invalid requests
+func TestCountBadRequest(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	assert.Equal(t, http.StatusBadRequest, post("/count?format=xml", "https://example.com").Code, "Expected an unknown format to be rejected")
+	assert.Equal(t, http.StatusBadRequest, post("/count", "\n\n").Code, "Expected an empty list to be rejected")
+
+	w := httptest.NewRecorder()
+	Handler(count).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/count", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code, "Expected urls to be posted")
+}
+
+// Test the count route responds with the error of a count which failed
+func TestCountError(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	failing := func(_ context.Context, _ []string) (models.Result, error) {
+		return models.Result{}, errors.New("exact and approximate counts cannot be merged")
+	}
+	w := httptest.NewRecorder()
+	Handler(failing).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/count", strings.NewReader("https://example.com")))
+	assert.Equal(t, http.StatusInternalServerError, w.Code, "Expected the count to fail")
+	assert.Equal(t, "exact and approximate counts cannot be merged\n", w.Body.String(), "Expected the error of the count")
+}
+
+// Test the service stops once the context is canceled
+func TestListenAndServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ListenAndServe(ctx, "127.0.0.1:0", Handler(count)) }()
+	cancel()
+	select {
+	case err := <-done:
+		assert.Nil(t, err, "Expected the service to stop cleanly")
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the service to stop")
+	}
+}
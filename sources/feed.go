@@ -0,0 +1,111 @@
+package sources
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/constants"
+)
+
+// feedLink is the link of an RSS item, in its text, or of an Atom entry, in its href
+type feedLink struct {
+	Href  string `xml:"href,attr"`
+	Rel   string `xml:"rel,attr"`
+	Value string `xml:",chardata"`
+}
+
+// feedItem is an RSS 2.0 or RSS 1.0 item, or an Atom entry
+type feedItem struct {
+	Links []feedLink `xml:"link"`
+	GUID  struct {
+		Value       string `xml:",chardata"`
+		IsPermaLink string `xml:"isPermaLink,attr"`
+	} `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	// Date is the dc:date of RSS 1.0
+	Date      string `xml:"date"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+}
+
+// link returns the url of the page of the item
+func (item feedItem) link() string {
+	for _, l := range item.Links {
+		if href := strings.TrimSpace(l.Href); href != constants.Empty && (l.Rel == constants.Empty || l.Rel == "alternate") {
+			return href
+		}
+		if value := strings.TrimSpace(l.Value); value != constants.Empty {
+			return value
+		}
+	}
+	// the guid of an RSS item is its permalink unless told otherwise
+	if item.GUID.IsPermaLink != "false" {
+		return strings.TrimSpace(item.GUID.Value)
+	}
+	return constants.Empty
+}
+
+// date returns the publication date of the item, its update date for Atom
+// entries which are not dated otherwise
+func (item feedItem) date() string {
+	for _, date := range []string{item.PubDate, item.Date, item.Published, item.Updated} {
+		if date != constants.Empty {
+			return date
+		}
+	}
+	return constants.Empty
+}
+
+// readFeed adds the links of the items of an RSS or Atom feed in the date range to the collector
+func readFeed(ctx context.Context, p string, w window, c *collector) error {
+	body, name, err := open(ctx, p)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	r, err := decompress(body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	skipped := 0
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || (start.Name.Local != "item" && start.Name.Local != "entry") {
+			continue
+		}
+		line, _ := decoder.InputPos()
+		var item feedItem
+		if err := decoder.DecodeElement(&item, &start); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if !w.contains(item.date()) {
+			skipped++
+			continue
+		}
+		c.source = name
+		link := item.link()
+		if link == constants.Empty {
+			c.reject(line, start.Name.Local, "no link")
+			continue
+		}
+		c.add(line, resolve(p, link))
+	}
+	if skipped > 0 {
+		log.Printf("Skipped %d items of %s outside the date range", skipped, name)
+	}
+	return nil
+}
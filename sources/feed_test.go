@@ -0,0 +1,77 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const rss = `<?xml version="1.0"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+<channel>
+  <title>Essays</title>
+  <link>https://example.com/</link>
+  <atom:link href="https://example.com/feed" rel="self"/>
+  <item><title>Old</title><link>https://example.com/old</link><pubDate>Sat, 24 Aug 2019 08:00:00 +0000</pubDate></item>
+  <item><title>New</title><link>/new</link><pubDate>Sun, 25 Aug 2019 08:00:00 GMT</pubDate></item>
+  <item><title>Guid</title><guid>https://example.com/guid</guid></item>
+  <item><title>Note</title><guid isPermaLink="false">note-1</guid></item>
+</channel>
+</rss>`
+
+const atom = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Essays</title>
+  <link href="https://example.com/"/>
+  <entry>
+    <title>Published</title>
+    <link rel="enclosure" href="https://example.com/audio.mp3"/>
+    <link href="https://example.com/published"/>
+    <published>2019-08-25T08:00:00Z</published>
+    <updated>2019-08-20T08:00:00Z</updated>
+  </entry>
+  <entry>
+    <title>Updated</title>
+    <link rel="alternate" href="https://example.com/updated"/>
+    <updated>2019-08-20T08:00:00Z</updated>
+  </entry>
+</feed>`
+
+// Test Read counts the links of the items of an RSS feed, resolved against the feed url
+func TestReadRSS(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(rss))
+	}))
+	defer server.Close()
+
+	list, err := Read(context.Background(), Input{Feeds: []string{server.URL + "/feed"}})
+	assert.Nil(t, err, "Expected no error reading the feed")
+	assert.Equal(t, []string{"https://example.com/old", server.URL + "/new", "https://example.com/guid"}, list.URLs, "Expected the links of the items")
+	assert.Equal(t, []models.RejectedLine{{Source: server.URL + "/feed", Line: 10, Text: "item", Reason: "no link"}}, list.Rejected, "Expected the item without a link to be rejected")
+
+	config.SetDateRange("2019-08-25", "")
+	list, err = Read(context.Background(), Input{Feeds: []string{server.URL + "/feed"}})
+	assert.Nil(t, err, "Expected no error reading the feed")
+	assert.Equal(t, []string{server.URL + "/new", "https://example.com/guid"}, list.URLs, "Expected the items published since the date and the undated ones")
+}
+
+// Test Read counts the alternate links of the entries of an Atom feed
+func TestReadAtom(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	path := writeList(t, t.TempDir(), "atom.xml", atom)
+
+	list, err := Read(context.Background(), Input{Feeds: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the feed")
+	assert.Equal(t, []string{"https://example.com/published", "https://example.com/updated"}, list.URLs, "Expected the alternate links of the entries")
+
+	config.SetDateRange("", "2019-08-24T00:00:00Z")
+	list, err = Read(context.Background(), Input{Feeds: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the feed")
+	assert.Equal(t, []string{"https://example.com/updated"}, list.URLs, "Expected the entries published until the time")
+}
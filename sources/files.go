@@ -0,0 +1,69 @@
+package sources
+
+import (
+	"io/fs"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/extract"
+)
+
+// FileURL returns the file url under which the local document at path is counted
+func FileURL(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String(), nil
+}
+
+// FilePath returns the path of the local document of a file url, and false for
+// any other url
+func FilePath(link string) (string, bool) {
+	if !strings.HasPrefix(link, "file://") {
+		return "", false
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+	return filepath.FromSlash(u.Path), true
+}
+
+// readPath adds the local document at path to the collector, or every document of a
+// supported format under it when it is a directory. A file given explicitly whose
+// format is not supported is rejected.
+func readPath(p string, c *collector) error {
+	files, err := expand(p)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		c.source = file
+		err := filepath.WalkDir(file, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if extract.FormatOf(path) == "" {
+				if path == file {
+					c.reject(0, path, "unsupported format")
+				}
+				return nil
+			}
+			link, err := FileURL(path)
+			if err != nil {
+				return err
+			}
+			c.addFile(path, link)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test the local documents are read as file urls, the directories walked for the supported formats
+func TestReadDocuments(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	dir := t.TempDir()
+	for _, name := range []string{"essay.md", "notes/draft.txt", "notes/book.epub", "notes/image.png"} {
+		path := filepath.Join(dir, name)
+		assert.Nil(t, os.MkdirAll(filepath.Dir(path), 0o755), "Expected no error creating the directory")
+		assert.Nil(t, os.WriteFile(path, []byte("text"), 0o644), "Expected no error writing the document")
+	}
+	image := filepath.Join(dir, "notes", "image.png")
+
+	list, err := Read(context.Background(), Input{Paths: []string{filepath.Join(dir, "*.md"), filepath.Join(dir, "notes"), image, filepath.Join(dir, "essay.md")}})
+	assert.Nil(t, err, "Expected no error reading the documents")
+	var paths []string
+	for _, link := range list.URLs {
+		path, ok := FilePath(link)
+		assert.True(t, ok, "Expected a file url")
+		paths = append(paths, path)
+	}
+	expected := []string{filepath.Join(dir, "essay.md"), filepath.Join(dir, "notes", "book.epub"), filepath.Join(dir, "notes", "draft.txt")}
+	assert.Equal(t, expected, paths, "Expected the documents of a supported format")
+	assert.Equal(t, 2, len(list.Rejected), "Expected the image and the repeated document to be rejected")
+	assert.Equal(t, "unsupported format", list.Rejected[0].Reason, "Expected the image to be rejected")
+	assert.Equal(t, "duplicate file", list.Rejected[1].Reason, "Expected the repeated document to be rejected")
+
+	_, err = Read(context.Background(), Input{Paths: []string{filepath.Join(dir, "missing.txt")}})
+	assert.NotNil(t, err, "Expected an error for a missing document")
+	_, ok := FilePath("https://example.com/essay.txt")
+	assert.False(t, ok, "Expected no path for an http url")
+}
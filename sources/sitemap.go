@@ -0,0 +1,192 @@
+package sources
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/constants"
+)
+
+// maxSitemapDepth bounds the nesting of sitemap indexes
+const maxSitemapDepth = 5
+
+// dateLayouts are the layouts of the sitemap lastmod (W3C datetime), the RSS
+// pubDate (RFC 822) and the Atom dates (RFC 3339)
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	time.DateOnly,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// ParseDate parses a date of a sitemap or a feed
+func ParseDate(date string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", date)
+}
+
+// window is the date range of the sitemap entries and feed items counted
+type window struct {
+	since time.Time
+	// until is excluded, the day after a date so that the date is included
+	until time.Time
+}
+
+// CheckDateRange validates the bounds of the date range, each one a date or a
+// RFC 3339 time, unbounded when empty
+func CheckDateRange(since, until string) error {
+	_, err := newWindow(since, until)
+	return err
+}
+
+func newWindow(since, until string) (window, error) {
+	var w window
+	var err error
+	if since != constants.Empty {
+		if w.since, err = ParseDate(since); err != nil {
+			return window{}, err
+		}
+	}
+	if until != constants.Empty {
+		if w.until, err = ParseDate(until); err != nil {
+			return window{}, err
+		}
+		if len(until) == len(time.DateOnly) {
+			w.until = w.until.AddDate(0, 0, 1)
+		} else {
+			w.until = w.until.Add(time.Nanosecond)
+		}
+	}
+	if !w.since.IsZero() && !w.until.IsZero() && !w.since.Before(w.until) {
+		return window{}, fmt.Errorf("the date range from %s until %s is empty", since, until)
+	}
+	return w, nil
+}
+
+// contains reports whether the date is in the range. Entries without a date, or
+// with one which cannot be parsed, are always counted.
+func (w window) contains(date string) bool {
+	if w.since.IsZero() && w.until.IsZero() {
+		return true
+	}
+	t, err := ParseDate(strings.TrimSpace(date))
+	if err != nil {
+		return true
+	}
+	return !t.Before(w.since) && (w.until.IsZero() || t.Before(w.until))
+}
+
+// resolve returns the reference as an absolute url, relative to the remote
+// document it was read from
+func resolve(base, ref string) string {
+	if !isRemote(base) {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// decompress returns the content of a gzipped document, such as a sitemap.xml.gz,
+// or the document itself
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+// sitemapEntry is a url of a urlset or a sitemap of a sitemap index
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// readSitemap adds the urls of a sitemap in the date range to the collector,
+// reading the sitemaps of an index in turn
+func readSitemap(ctx context.Context, p string, w window, c *collector, visited map[string]bool, depth int) error {
+	if visited[p] {
+		return nil
+	}
+	visited[p] = true
+	body, name, err := open(ctx, p)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	r, err := decompress(body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	var sitemaps []string
+	skipped := 0
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || (start.Name.Local != "url" && start.Name.Local != "sitemap") {
+			continue
+		}
+		line, _ := decoder.InputPos()
+		var entry sitemapEntry
+		if err := decoder.DecodeElement(&entry, &start); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		loc := strings.TrimSpace(entry.Loc)
+		switch {
+		case start.Name.Local == "sitemap":
+			sitemaps = append(sitemaps, resolve(p, loc))
+		case !w.contains(entry.LastMod):
+			skipped++
+		default:
+			c.source = name
+			c.add(line, loc)
+		}
+	}
+	if skipped > 0 {
+		log.Printf("Skipped %d urls of %s outside the date range", skipped, name)
+	}
+
+	if len(sitemaps) > 0 && depth == maxSitemapDepth {
+		return fmt.Errorf("%s: sitemap indexes nested deeper than %d", name, maxSitemapDepth)
+	}
+	for _, sitemap := range sitemaps {
+		if err := readSitemap(ctx, sitemap, w, c, visited, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const urlset = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/2019/08/24/old</loc><lastmod>2019-08-24</lastmod></url>
+  <url>
+    <loc> https://example.com/2019/08/25/new </loc>
+    <lastmod>2019-08-25T10:30:00+02:00</lastmod>
+  </url>
+  <url><loc>https://example.com/undated</loc></url>
+  <url><loc>/relative</loc></url>
+</urlset>`
+
+// sitemapServer serves a sitemap index of a gzipped urlset and of the index itself
+func sitemapServer(t *testing.T) *httptest.Server {
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte(urlset))
+	assert.Nil(t, gz.Close(), "Expected no error gzipping the urlset")
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			_, _ = w.Write([]byte(`<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>/posts.xml.gz</loc></sitemap>
+  <sitemap><loc>/sitemap.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/posts.xml.gz":
+			_, _ = w.Write(gzipped.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// Test Read expands a sitemap index into the urls of its sitemaps, once each
+func TestReadSitemap(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	server := sitemapServer(t)
+	defer server.Close()
+
+	list, err := Read(context.Background(), Input{Sitemaps: []string{server.URL + "/sitemap.xml"}})
+	assert.Nil(t, err, "Expected no error reading the sitemap index")
+	assert.Equal(t, []string{"https://example.com/2019/08/24/old", "https://example.com/2019/08/25/new", "https://example.com/undated"}, list.URLs, "Expected the urls of the urlset")
+	assert.Equal(t, []models.RejectedLine{{Source: server.URL + "/posts.xml.gz", Line: 9, Text: "/relative", Reason: "not an http url"}}, list.Rejected, "Expected the relative url to be rejected")
+
+	_, err = Read(context.Background(), Input{Sitemaps: []string{server.URL + "/missing.xml"}})
+	assert.NotNil(t, err, "Expected an error for a missing sitemap")
+}
+
+// Test Read only counts the sitemap urls last modified in the date range
+func TestReadSitemapDateRange(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	path := writeList(t, t.TempDir(), "sitemap.xml", urlset)
+
+	config.SetDateRange("2019-08-25", "")
+	list, err := Read(context.Background(), Input{Sitemaps: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the sitemap")
+	assert.Equal(t, []string{"https://example.com/2019/08/25/new", "https://example.com/undated"}, list.URLs, "Expected the urls modified since the date and the undated one")
+
+	_ = config.InitConfig(devConfigFilePath)
+	config.SetDateRange("", "2019-08-24")
+	list, err = Read(context.Background(), Input{Sitemaps: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the sitemap")
+	assert.Equal(t, []string{"https://example.com/2019/08/24/old", "https://example.com/undated"}, list.URLs, "Expected the until date to be included")
+
+	config.SetDateRange("2019-08-26", "")
+	_, err = Read(context.Background(), Input{Sitemaps: []string{path}})
+	assert.NotNil(t, err, "Expected an error for an empty date range")
+}
+
+// Test ParseDate parses the dates of sitemaps, RSS and Atom
+func TestParseDate(t *testing.T) {
+	expected := time.Date(2019, 8, 25, 8, 30, 0, 0, time.UTC)
+	for _, date := range []string{"2019-08-25T08:30:00Z", "2019-08-25T10:30+02:00", "Sun, 25 Aug 2019 08:30:00 +0000", "Sun, 25 Aug 2019 08:30:00 GMT", "25 Aug 2019 08:30:00 +0000"} {
+		parsed, err := ParseDate(date)
+		assert.Nil(t, err, "Expected no error parsing %s", date)
+		assert.True(t, expected.Equal(parsed), "Date mismatch for %s", date)
+	}
+	_, err := ParseDate("yesterday")
+	assert.NotNil(t, err, "Expected an error for an invalid date")
+	assert.NotNil(t, CheckDateRange("2019-08-25", "2019-08-01"), "Expected an error for an empty date range")
+	assert.Nil(t, CheckDateRange("2019-08-25", "2019-08-25"), "Expected a single day to be a valid date range")
+}
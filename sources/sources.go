@@ -0,0 +1,331 @@
+package sources
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/constants"
+	"github.com/joshy-joy/essay-word-counter/externals"
+	"github.com/joshy-joy/essay-word-counter/models"
+)
+
+// Formats of the url lists
+const (
+	Text  = "text"
+	CSV   = "csv"
+	JSONL = "jsonl"
+)
+
+// Formats lists the supported formats of url lists
+var Formats = []string{Text, CSV, JSONL}
+
+// Stdin is the path of the url list read from the standard input
+const Stdin = "-"
+
+var (
+	stdin               io.Reader = os.Stdin
+	externalsFetchEssay           = externals.FetchEssay
+)
+
+// List is the urls read from url lists and the lines which were rejected
+type List struct {
+	URLs     []string
+	Rejected []models.RejectedLine
+}
+
+// IsFormat reports whether format is supported, an empty format being chosen
+// from the extension of every list
+func IsFormat(format string) bool {
+	if format == constants.Empty {
+		return true
+	}
+	for _, f := range Formats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Input names the sources of the urls of a run
+type Input struct {
+	// Lists are url lists, files, glob patterns, http urls or Stdin
+	Lists []string
+	// Sitemaps are sitemaps or sitemap indexes, files or http urls
+	Sitemaps []string
+	// Feeds are RSS or Atom feeds, files or http urls
+	Feeds []string
+	// Paths are local documents or directories of documents, counted as file urls
+	Paths []string
+}
+
+// Configured returns the configured sources, the default file when there is none
+func Configured() Input {
+	cfg := config.Get()
+	in := Input{Lists: cfg.Input.Files, Sitemaps: cfg.Input.Sitemaps, Feeds: cfg.Input.Feeds, Paths: cfg.Input.Paths}
+	if len(in.Lists)+len(in.Sitemaps)+len(in.Feeds)+len(in.Paths) == 0 {
+		in.Lists = []string{cfg.DefaultFilePath}
+	}
+	return in
+}
+
+// Read reads the urls of the lists, then of the sitemaps and feeds whose entries
+// are in the configured date range, and the local documents. Every line or entry which is not a valid http
+// url, or repeats one, is rejected and logged.
+func Read(ctx context.Context, in Input) (List, error) {
+	c := &collector{seen: make(map[string]bool)}
+	for _, p := range in.Lists {
+		files, err := expand(p)
+		if err != nil {
+			return List{}, err
+		}
+		for _, file := range files {
+			if err := readList(ctx, file, c); err != nil {
+				return List{}, err
+			}
+		}
+	}
+	if len(in.Sitemaps)+len(in.Feeds) > 0 {
+		w, err := newWindow(config.Get().Input.Since, config.Get().Input.Until)
+		if err != nil {
+			return List{}, err
+		}
+		visited := make(map[string]bool)
+		for _, p := range in.Sitemaps {
+			if err := readSitemap(ctx, p, w, c, visited, 0); err != nil {
+				return List{}, err
+			}
+		}
+		for _, p := range in.Feeds {
+			if err := readFeed(ctx, p, w, c); err != nil {
+				return List{}, err
+			}
+		}
+	}
+	for _, p := range in.Paths {
+		if err := readPath(p, c); err != nil {
+			return List{}, err
+		}
+	}
+	for _, r := range c.list.Rejected {
+		log.Printf("Rejected %s:%d %q: %s", r.Source, r.Line, r.Text, r.Reason)
+	}
+	return c.list, nil
+}
+
+// collector validates the urls of the lists being read
+type collector struct {
+	list List
+	seen map[string]bool
+	// source is the name of the list being read
+	source string
+}
+
+// add keeps the url on the line, unless it is invalid or already kept
+func (c *collector) add(line int, text string) {
+	u, err := url.Parse(text)
+	switch {
+	case err != nil:
+		c.reject(line, text, "invalid url")
+	case u.Scheme != "http" && u.Scheme != "https":
+		c.reject(line, text, "not an http url")
+	case u.Host == constants.Empty:
+		c.reject(line, text, "no host")
+	case c.seen[text]:
+		c.reject(line, text, "duplicate url")
+	default:
+		c.seen[text] = true
+		c.list.URLs = append(c.list.URLs, text)
+	}
+}
+
+// addFile keeps the file url of the local document at path, unless it is already kept
+func (c *collector) addFile(path, link string) {
+	if c.seen[link] {
+		c.reject(0, path, "duplicate file")
+		return
+	}
+	c.seen[link] = true
+	c.list.URLs = append(c.list.URLs, link)
+}
+
+func (c *collector) reject(line int, text, reason string) {
+	c.list.Rejected = append(c.list.Rejected, models.RejectedLine{Source: c.source, Line: line, Text: text, Reason: reason})
+}
+
+// expand returns the files matching a glob pattern, or the path itself
+func expand(p string) ([]string, error) {
+	if p == Stdin || isRemote(p) || !strings.ContainsAny(p, "*?[") {
+		return []string{p}, nil
+	}
+	files, err := filepath.Glob(p)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no url list matches %s", p)
+	}
+	return files, nil
+}
+
+func isRemote(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://")
+}
+
+// open returns the content of a file, an http url or Stdin, and the name it is reported under
+func open(ctx context.Context, p string) (io.ReadCloser, string, error) {
+	switch {
+	case p == Stdin:
+		return io.NopCloser(stdin), "stdin", nil
+	case isRemote(p):
+		body, err := externalsFetchEssay(ctx, "GET", p)
+		return body, p, err
+	}
+	f, err := os.Open(p)
+	return f, p, err
+}
+
+// readList adds the urls of a single list to the collector
+func readList(ctx context.Context, p string, c *collector) error {
+	r, name, err := open(ctx, p)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	c.source = name
+	if isRemote(p) {
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+	}
+
+	switch format(p) {
+	case CSV:
+		return readCSV(r, c)
+	case JSONL:
+		return readJSONL(r, c)
+	}
+	return readText(r, c)
+}
+
+// format returns the configured format of the lists, or the one of the extension of p
+func format(p string) string {
+	if f := config.Get().Input.Format; f != constants.Empty {
+		return f
+	}
+	switch strings.ToLower(path.Ext(p)) {
+	case ".csv":
+		return CSV
+	case ".jsonl", ".ndjson":
+		return JSONL
+	}
+	return Text
+}
+
+// byteOrderMark may start the first line of a list saved by a text editor
+const byteOrderMark = "\ufeff"
+
+// readText reads a url per line, skipping blank lines and comments starting with #
+func readText(r io.Reader, c *collector) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			text = strings.TrimPrefix(text, byteOrderMark)
+		}
+		if text = strings.TrimSpace(stripComment(text)); text != constants.Empty {
+			c.add(line, text)
+		}
+	}
+	return scanner.Err()
+}
+
+// stripComment removes a comment starting the line or following a space, a # in
+// a url being its fragment
+func stripComment(text string) string {
+	for i, r := range text {
+		if r == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t') {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// readCSV reads the urls of the configured column, named in the header
+func readCSV(r io.Reader, c *collector) error {
+	name := config.Get().Input.Column
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	column := -1
+	for i, h := range header {
+		if i == 0 {
+			h = strings.TrimPrefix(h, byteOrderMark)
+		}
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			column = i
+		}
+	}
+	if column < 0 {
+		return fmt.Errorf("%s has no %q column", c.source, name)
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := reader.FieldPos(0)
+		if column >= len(record) {
+			c.reject(line, strings.Join(record, ","), fmt.Sprintf("no %q column", name))
+			continue
+		}
+		if text := strings.TrimSpace(record[column]); text != constants.Empty {
+			c.add(line, text)
+		}
+	}
+}
+
+// readJSONL reads the urls of the configured field of a JSON object per line
+func readJSONL(r io.Reader, c *collector) error {
+	name := config.Get().Input.Column
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == constants.Empty {
+			continue
+		}
+		var object map[string]any
+		if err := json.Unmarshal([]byte(text), &object); err != nil {
+			c.reject(line, text, "invalid json")
+			continue
+		}
+		value, ok := object[name].(string)
+		if !ok {
+			c.reject(line, text, fmt.Sprintf("no %q field", name))
+			continue
+		}
+		c.add(line, strings.TrimSpace(value))
+	}
+	return scanner.Err()
+}
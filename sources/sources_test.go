@@ -0,0 +1,124 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshy-joy/essay-word-counter/config"
+	"github.com/joshy-joy/essay-word-counter/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const devConfigFilePath = "../resources/dev/config.yml"
+
+// writeList writes a url list to a temporary directory and returns its path
+func writeList(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0644), "Expected no error writing the list")
+	return path
+}
+
+// Test Read skips blank lines and comments, and rejects the lines which are not http urls
+func TestReadText(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	path := writeList(t, t.TempDir(), "urls.txt", "\ufeff# essays\r\nhttps://example.com/1\r\n\r\n  https://example.com/2#intro  # second essay\nftp://example.com/3\nhttps:///4\nexample.com/5\nhttps://example.com/1\n")
+
+	list, err := Read(context.Background(), Input{Lists: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the list")
+	assert.Equal(t, []string{"https://example.com/1", "https://example.com/2#intro"}, list.URLs, "Expected the urls without comments")
+	assert.Equal(t, []models.RejectedLine{
+		{Source: path, Line: 5, Text: "ftp://example.com/3", Reason: "not an http url"},
+		{Source: path, Line: 6, Text: "https:///4", Reason: "no host"},
+		{Source: path, Line: 7, Text: "example.com/5", Reason: "not an http url"},
+		{Source: path, Line: 8, Text: "https://example.com/1", Reason: "duplicate url"},
+	}, list.Rejected, "Rejected lines mismatch")
+}
+
+// Test Read reads several lists, glob patterns and stdin in order
+func TestReadPaths(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	dir := t.TempDir()
+	writeList(t, dir, "a.txt", "https://example.com/a\n")
+	writeList(t, dir, "b.txt", "https://example.com/b\n")
+	other := writeList(t, dir, "other.list", "https://example.com/other\n")
+	stdin = strings.NewReader("https://example.com/stdin\n")
+	defer func() { stdin = os.Stdin }()
+
+	list, err := Read(context.Background(), Input{Lists: []string{other, filepath.Join(dir, "*.txt"), Stdin}})
+	assert.Nil(t, err, "Expected no error reading the lists")
+	assert.Equal(t, []string{"https://example.com/other", "https://example.com/a", "https://example.com/b", "https://example.com/stdin"}, list.URLs, "Expected the urls of every list")
+
+	_, err = Read(context.Background(), Input{Lists: []string{filepath.Join(dir, "*.csv")}})
+	assert.NotNil(t, err, "Expected an error for a pattern matching no list")
+	_, err = Read(context.Background(), Input{Lists: []string{filepath.Join(dir, "missing.txt")}})
+	assert.NotNil(t, err, "Expected an error for a missing list")
+}
+
+// Test Read takes the urls of csv lists from the configured column
+func TestReadCSV(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	dir := t.TempDir()
+	path := writeList(t, dir, "urls.csv", "title,URL\n\"Sony, cart\",https://example.com/1\nshort\nEmpty,\n")
+
+	list, err := Read(context.Background(), Input{Lists: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the csv list")
+	assert.Equal(t, []string{"https://example.com/1"}, list.URLs, "Expected the urls of the url column")
+	assert.Equal(t, []models.RejectedLine{{Source: path, Line: 3, Text: "short", Reason: `no "url" column`}}, list.Rejected, "Expected the short record to be rejected")
+
+	config.SetInputColumn("link")
+	_, err = Read(context.Background(), Input{Lists: []string{path}})
+	assert.NotNil(t, err, "Expected an error for a missing column")
+}
+
+// Test Read takes the urls of jsonl lists from the configured field
+func TestReadJSONL(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	path := writeList(t, t.TempDir(), "urls.list", "{\"url\": \"https://example.com/1\"}\n\n{\"link\": \"https://example.com/2\"}\nnot json\n")
+	config.SetInputFormat(JSONL)
+
+	list, err := Read(context.Background(), Input{Lists: []string{path}})
+	assert.Nil(t, err, "Expected no error reading the jsonl list")
+	assert.Equal(t, []string{"https://example.com/1"}, list.URLs, "Expected the urls of the url field")
+	assert.Equal(t, []models.RejectedLine{
+		{Source: path, Line: 3, Text: `{"link": "https://example.com/2"}`, Reason: `no "url" field`},
+		{Source: path, Line: 4, Text: "not json", Reason: "invalid json"},
+	}, list.Rejected, "Rejected lines mismatch")
+}
+
+// Test Read fetches remote lists, choosing the format from the extension of the url path
+func TestReadRemote(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/urls.csv" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("url\nhttps://example.com/1\n"))
+	}))
+	defer server.Close()
+
+	list, err := Read(context.Background(), Input{Lists: []string{server.URL + "/urls.csv?token=1"}})
+	assert.Nil(t, err, "Expected no error reading the remote list")
+	assert.Equal(t, []string{"https://example.com/1"}, list.URLs, "Expected the urls of the remote csv list")
+
+	_, err = Read(context.Background(), Input{Lists: []string{server.URL + "/missing.txt"}})
+	assert.NotNil(t, err, "Expected an error for a missing remote list")
+}
+
+// Test Configured falls back to the default file path
+func TestConfigured(t *testing.T) {
+	_ = config.InitConfig(devConfigFilePath)
+	assert.Equal(t, []string{config.Get().DefaultFilePath}, Configured().Lists, "Expected the default file path")
+	config.SetFeeds([]string{"feed.xml"})
+	assert.Empty(t, Configured().Lists, "Expected no default file with a feed")
+	assert.Equal(t, []string{"feed.xml"}, Configured().Feeds, "Expected the configured feeds")
+	config.SetFilePaths([]string{"a.txt", Stdin})
+	assert.Equal(t, []string{"a.txt", Stdin}, Configured().Lists, "Expected the configured lists")
+	assert.True(t, IsFormat(""), "Expected an empty format to be supported")
+	assert.False(t, IsFormat("xml"), "Expected xml not to be supported")
+}
@@ -0,0 +1,277 @@
+package tokens
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Policies of the token rules
+const (
+	Keep      = "keep"
+	Drop      = "drop"
+	Split     = "split"
+	Expand    = "expand"
+	Join      = "join"
+	Normalize = "normalize"
+)
+
+// NumberToken replaces every number when numbers are normalized
+const NumberToken = "<num>"
+
+// Rules configures how the special tokens are handled. An empty policy means Keep.
+type Rules struct {
+	// Contractions: keep ("don't"), split ("do", "n't") or expand ("do", "not")
+	Contractions string `yaml:"contractions"`
+	// Hyphens: keep ("e-mail"), split ("e", "mail") or join ("email")
+	Hyphens string `yaml:"hyphens"`
+	// Numbers: keep ("3.5"), drop or normalize (NumberToken)
+	Numbers string `yaml:"numbers"`
+	// URLs, Emails, Hashtags and Mentions: keep whole or drop
+	URLs     string `yaml:"urls"`
+	Emails   string `yaml:"emails"`
+	Hashtags string `yaml:"hashtags"`
+	Mentions string `yaml:"mentions"`
+	// Elisions are the elided words of the language of the text, such as the french
+	// "l'", split from the word they precede along with the contractions. Other words
+	// with an apostrophe, such as "o'brien", are kept whole.
+	Elisions []string `yaml:"-" json:"-"`
+}
+
+// Validate checks the policy of every rule is one it supports
+func (r Rules) Validate() error {
+	for _, rule := range []struct {
+		name, policy string
+		policies     []string
+	}{
+		{"contractions", r.Contractions, []string{Keep, Split, Expand}},
+		{"hyphens", r.Hyphens, []string{Keep, Split, Join}},
+		{"numbers", r.Numbers, []string{Keep, Drop, Normalize}},
+		{"urls", r.URLs, []string{Keep, Drop}},
+		{"emails", r.Emails, []string{Keep, Drop}},
+		{"hashtags", r.Hashtags, []string{Keep, Drop}},
+		{"mentions", r.Mentions, []string{Keep, Drop}},
+	} {
+		if rule.policy != "" && !slices.Contains(rule.policies, rule.policy) {
+			return fmt.Errorf("unknown %s token policy %q, expected one of %s", rule.name, rule.policy, strings.Join(rule.policies, ", "))
+		}
+	}
+	return nil
+}
+
+var (
+	urlRe     = regexp.MustCompile(`^(?i)(https?://|www\.)\S+$`)
+	emailRe   = regexp.MustCompile(`^[\p{L}\p{N}._%+-]+@[\p{L}\p{N}-]+(\.[\p{L}\p{N}-]+)+$`)
+	hashtagRe = regexp.MustCompile(`^#\p{L}[\p{L}\p{N}_]*$`)
+	mentionRe = regexp.MustCompile(`^@[\p{L}\p{N}_]+$`)
+)
+
+// expansions of the english contractions, the possessive "'s" expands to nothing
+var expansions = map[string][]string{
+	"can't": {"can", "not"}, "won't": {"will", "not"}, "shan't": {"shall", "not"},
+	"ain't": {"is", "not"}, "let's": {"let", "us"}, "i'm": {"i", "am"},
+	"it's": {"it", "is"}, "he's": {"he", "is"}, "she's": {"she", "is"},
+	"that's": {"that", "is"}, "what's": {"what", "is"}, "there's": {"there", "is"},
+	"here's": {"here", "is"}, "who's": {"who", "is"}, "where's": {"where", "is"},
+}
+
+// suffixes of the english clitics, expanded when not found in expansions
+var clitics = []struct{ suffix, expanded string }{
+	{"n't", "not"}, {"'re", "are"}, {"'ve", "have"}, {"'ll", "will"}, {"'d", "would"}, {"'m", "am"}, {"'s", ""},
+}
+
+// Tokenize splits a sentence into lower-cased words according to the rules.
+// Runs of characters written without spaces (chinese, japanese, thai) are
+// passed to segment when not nil, and kept as a single word otherwise.
+func Tokenize(sentence string, rules Rules, segment func(string) []string) []string {
+	var tokens []string
+	for _, chunk := range strings.Fields(sentence) {
+		chunk = strings.ReplaceAll(chunk, "’", "'")
+		trimmed := strings.TrimRightFunc(strings.TrimLeft(chunk, "\"'([{«“‘<"), isTrailingPunct)
+
+		if special, policy, ok := classify(trimmed, rules); ok {
+			if policy != Drop {
+				tokens = append(tokens, strings.ToLower(special))
+			}
+			continue
+		}
+		for _, word := range scan(chunk, segment) {
+			tokens = append(tokens, applyRules(word, rules)...)
+		}
+	}
+	return tokens
+}
+
+// classify recognises the tokens which are kept whole or dropped
+func classify(token string, rules Rules) (string, string, bool) {
+	// most words cannot be special tokens, avoid the regular expressions for them
+	if !strings.ContainsAny(token, "@#:.") {
+		return token, Keep, false
+	}
+	switch {
+	case urlRe.MatchString(token):
+		return token, rules.URLs, true
+	case emailRe.MatchString(token):
+		return token, rules.Emails, true
+	case hashtagRe.MatchString(token):
+		return token, rules.Hashtags, true
+	case mentionRe.MatchString(token):
+		return token, rules.Mentions, true
+	}
+	return token, Keep, false
+}
+
+// scan splits a chunk into words made of letters, marks and digits, keeping
+// apostrophes and hyphens between letters and separators between digits
+func scan(chunk string, segment func(string) []string) []string {
+	runes := []rune(chunk)
+	var words []string
+	var word []rune
+	segmented := false
+	flush := func() {
+		if len(word) == 0 {
+			return
+		}
+		if segmented && segment != nil {
+			words = append(words, segment(string(word))...)
+		} else {
+			words = append(words, string(word))
+		}
+		word = word[:0]
+	}
+
+	for i, r := range runes {
+		isAlnum := unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.M, r)
+		switch {
+		case isAlnum && (len(word) == 0 || isSegmented(r) == segmented || unicode.Is(unicode.M, r)):
+			if len(word) == 0 {
+				segmented = isSegmented(r)
+			}
+			word = append(word, r)
+		case isAlnum:
+			// switching between a segmented script and another one
+			flush()
+			segmented = isSegmented(r)
+			word = append(word, r)
+		case len(word) > 0 && i+1 < len(runes) && isConnector(word[len(word)-1], r, runes[i+1]):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// isConnector reports whether r joins the characters around it into one word
+func isConnector(prev, r, next rune) bool {
+	switch r {
+	case '\'':
+		return unicode.IsLetter(prev) && unicode.IsLetter(next)
+	case '-':
+		return (unicode.IsLetter(prev) || unicode.IsNumber(prev)) && (unicode.IsLetter(next) || unicode.IsNumber(next))
+	case '.', ',':
+		return unicode.IsDigit(prev) && unicode.IsDigit(next)
+	}
+	return false
+}
+
+// applyRules lower-cases the word and applies the number, contraction and hyphen policies
+func applyRules(word string, rules Rules) []string {
+	word = strings.ToLower(word)
+	if isNumber(word) {
+		switch rules.Numbers {
+		case Drop:
+			return nil
+		case Normalize:
+			return []string{NumberToken}
+		}
+		return []string{word}
+	}
+
+	var parts []string
+	if strings.Contains(word, "'") {
+		parts = contraction(word, rules)
+	} else {
+		parts = []string{word}
+	}
+
+	var tokens []string
+	for _, part := range parts {
+		if !strings.Contains(part, "-") {
+			tokens = append(tokens, part)
+			continue
+		}
+		switch rules.Hyphens {
+		case Split:
+			tokens = append(tokens, strings.Split(part, "-")...)
+		case Join:
+			tokens = append(tokens, strings.ReplaceAll(part, "-", ""))
+		default:
+			tokens = append(tokens, part)
+		}
+	}
+	return tokens
+}
+
+// contraction applies the contraction policy to a word containing an apostrophe
+func contraction(word string, rules Rules) []string {
+	policy := rules.Contractions
+	if policy != Split && policy != Expand {
+		return []string{word}
+	}
+	// elisions such as the french "l'histoire" are split after the apostrophe
+	for _, elision := range rules.Elisions {
+		if strings.HasPrefix(word, elision) && len(word) > len(elision) {
+			return []string{elision, word[len(elision):]}
+		}
+	}
+	if policy == Expand {
+		if expanded, ok := expansions[word]; ok {
+			return expanded
+		}
+	}
+	for _, c := range clitics {
+		if !strings.HasSuffix(word, c.suffix) || len(word) == len(c.suffix) {
+			continue
+		}
+		stem := strings.TrimSuffix(word, c.suffix)
+		if policy == Split {
+			return []string{stem, c.suffix}
+		}
+		if c.expanded == "" {
+			// possessive
+			return []string{stem}
+		}
+		return []string{stem, c.expanded}
+	}
+	return []string{word}
+}
+
+func isNumber(word string) bool {
+	hasDigit := false
+	for _, r := range word {
+		switch {
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case r != '.' && r != ',':
+			return false
+		}
+	}
+	return hasDigit
+}
+
+func isTrailingPunct(r rune) bool {
+	return strings.ContainsRune(".,;:!?\"')]}»”’>", r)
+}
+
+// isSegmented reports whether the rune belongs to a script written without spaces
+func isSegmented(r rune) bool {
+	// thai is the first of these scripts in the unicode table
+	if r < 0x0E00 {
+		return false
+	}
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai) || r == 'ー'
+}
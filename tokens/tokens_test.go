@@ -0,0 +1,69 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Tokenize with the default rules keeping every token
+func TestTokenizeKeep(t *testing.T) {
+	tokens := Tokenize(`"Don't" e-mail me 3.5 or 1,000 times at john@example.com, see https://example.com/a?b=1. #Go @joshy`, Rules{}, nil)
+	expected := []string{"don't", "e-mail", "me", "3.5", "or", "1,000", "times", "at", "john@example.com", "see", "https://example.com/a?b=1", "#go", "@joshy"}
+	assert.Equal(t, expected, tokens, "Tokens kept incorrectly")
+}
+
+// Test Tokenize dropping the special tokens and numbers
+func TestTokenizeDrop(t *testing.T) {
+	rules := Rules{Numbers: Drop, URLs: Drop, Emails: Drop, Hashtags: Drop, Mentions: Drop}
+	tokens := Tokenize("Mail john@example.com about www.example.com 42 times #go @joshy", rules, nil)
+	assert.Equal(t, []string{"mail", "about", "times"}, tokens, "Special tokens dropped incorrectly")
+}
+
+// Test Tokenize with the contraction policies
+func TestTokenizeContractions(t *testing.T) {
+	text := "I can't say it's John's, they're sure l'histoire"
+	elisions := []string{"l'", "qu'"}
+	assert.Equal(t, []string{"i", "ca", "n't", "say", "it", "'s", "john", "'s", "they", "'re", "sure", "l'", "histoire"},
+		Tokenize(text, Rules{Contractions: Split, Elisions: elisions}, nil), "Contractions split incorrectly")
+	assert.Equal(t, []string{"i", "can", "not", "say", "it", "is", "john", "they", "are", "sure", "l'", "histoire"},
+		Tokenize(text, Rules{Contractions: Expand, Elisions: elisions}, nil), "Contractions expanded incorrectly")
+}
+
+// Test Tokenize only splits the elisions of the language, keeping names and
+// possessives whole
+func TestTokenizeElisions(t *testing.T) {
+	text := "O'Brien's rock'n'roll in l'histoire"
+	assert.Equal(t, []string{"o'brien", "'s", "rock'n'roll", "in", "l'histoire"},
+		Tokenize(text, Rules{Contractions: Split}, nil), "Expected words with an apostrophe to be kept without elisions")
+	assert.Equal(t, []string{"o'brien", "rock'n'roll", "in", "l'", "histoire"},
+		Tokenize(text, Rules{Contractions: Expand, Elisions: []string{"l'"}}, nil), "Expected only the elisions to be split")
+	assert.Equal(t, []string{"lorsqu'", "il", "l'", "a"},
+		Tokenize("Lorsqu'il l'a", Rules{Contractions: Split, Elisions: []string{"l'", "qu'", "lorsqu'"}}, nil), "Expected the listed elisions to be split")
+}
+
+// Test Tokenize with the hyphen and number policies
+func TestTokenizeHyphensAndNumbers(t *testing.T) {
+	text := "A well-known e-mail costs 3.50 - not 1,000"
+	assert.Equal(t, []string{"a", "well", "known", "e", "mail", "costs", "3.50", "not", "1,000"},
+		Tokenize(text, Rules{Hyphens: Split}, nil), "Hyphens split incorrectly")
+	assert.Equal(t, []string{"a", "wellknown", "email", "costs", NumberToken, "not", NumberToken},
+		Tokenize(text, Rules{Hyphens: Join, Numbers: Normalize}, nil), "Hyphens joined incorrectly")
+}
+
+// Test Tokenize passes the runs of segmented scripts to the segmenter
+func TestTokenizeSegmented(t *testing.T) {
+	segment := func(text string) []string { return strings.Split(text, "") }
+	tokens := Tokenize("Apple的新手机。iPhone", Rules{}, segment)
+	assert.Equal(t, []string{"apple", "的", "新", "手", "机", "iphone"}, tokens, "Segmented runs tokenized incorrectly")
+	assert.Equal(t, []string{"apple", "的新手机", "iphone"}, Tokenize("Apple的新手机。iPhone", Rules{}, nil), "Expected segmented runs to be kept without segmenter")
+}
+
+// Test Validate rejects the policies a rule does not support
+func TestRulesValidate(t *testing.T) {
+	assert.Nil(t, Rules{Contractions: Expand, Hyphens: Join, Numbers: Normalize, URLs: Drop}.Validate(), "Expected the rules to be valid")
+	assert.Nil(t, Rules{}.Validate(), "Expected empty policies to be valid")
+	assert.EqualError(t, Rules{Hyphens: Expand}.Validate(), `unknown hyphens token policy "expand", expected one of keep, split, join`, "Expected the hyphen policy to be rejected")
+	assert.NotNil(t, Rules{Mentions: "Drop"}.Validate(), "Expected policies to be case sensitive")
+}
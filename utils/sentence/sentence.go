@@ -0,0 +1,126 @@
+package sentence
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ParagraphBreak separates the text of two block elements in the extracted content
+const ParagraphBreak = "\n\n"
+
+var paragraphRe = regexp.MustCompile(`\n\s*\n`)
+
+// abbreviations which are usually followed by a period without ending the sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "mt": true, "vs": true, "etc": true, "inc": true, "ltd": true, "co": true,
+	"corp": true, "no": true, "vol": true, "fig": true, "approx": true, "dept": true,
+	"est": true, "gen": true, "gov": true, "jan": true, "feb": true, "mar": true,
+	"apr": true, "jun": true, "jul": true, "aug": true, "sep": true, "sept": true,
+	"oct": true, "nov": true, "dec": true,
+}
+
+// Paragraphs splits the text at block element boundaries, i.e. blank lines
+func Paragraphs(text string) []string {
+	var paragraphs []string
+	for _, p := range paragraphRe.Split(text, -1) {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return paragraphs
+}
+
+// Split splits the text into sentences. A sentence never crosses a paragraph boundary.
+func Split(text string) []string {
+	var sentences []string
+	for _, p := range Paragraphs(text) {
+		sentences = append(sentences, splitParagraph(p)...)
+	}
+	return sentences
+}
+
+func splitParagraph(paragraph string) []string {
+	runes := []rune(paragraph)
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !isTerminator(runes[i]) {
+			continue
+		}
+		end := i
+		// consume the whole run of terminators, e.g. "?!" or "..."
+		for end+1 < len(runes) && isTerminator(runes[end+1]) {
+			end++
+		}
+		terminators := string(runes[i : end+1])
+		// closing quotes and brackets belong to the sentence they end
+		for end+1 < len(runes) && isCloser(runes[end+1]) {
+			end++
+		}
+		i = end
+		if end+1 < len(runes) && !unicode.IsSpace(runes[end+1]) {
+			// decimals, urls and the like: "3.5", "example.com"
+			continue
+		}
+		if terminators == "." && isAbbreviation(runes[start:end+1]) {
+			continue
+		}
+		// a period or an ellipsis followed by a lower case word does not end the sentence
+		if !strings.ContainsAny(terminators, "!?") && startsLowercase(runes[end+1:]) {
+			continue
+		}
+		if s := strings.TrimSpace(string(runes[start : end+1])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = end + 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+func isTerminator(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func isCloser(r rune) bool {
+	return strings.ContainsRune("\"')]}»”’", r)
+}
+
+// isAbbreviation reports whether the last word of the text, ending with a period,
+// is a known abbreviation, an initial ("J.") or a dotted acronym ("e.g.", "U.S.")
+func isAbbreviation(text []rune) bool {
+	// only the last word matters, so there is no need to look further back
+	start := len(text)
+	for start > 0 && !unicode.IsSpace(text[start-1]) {
+		start--
+	}
+	word := strings.TrimLeft(string(text[start:]), "\"'([{«“‘")
+	word = strings.TrimRight(word, "\"')]}»”’")
+	if !strings.HasSuffix(word, ".") || strings.HasSuffix(word, "..") {
+		return false
+	}
+	word = strings.ToLower(strings.TrimSuffix(word, "."))
+	if abbreviations[word] {
+		return true
+	}
+	if len([]rune(word)) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return true
+	}
+	return strings.Contains(word, ".") && !strings.ContainsFunc(word, unicode.IsDigit)
+}
+
+// startsLowercase reports whether the next word starts with a lower case letter,
+// meaning the period did not end the sentence
+func startsLowercase(rest []rune) bool {
+	for _, r := range rest {
+		if unicode.IsSpace(r) || strings.ContainsRune("\"'([{«“‘", r) {
+			continue
+		}
+		return unicode.IsLower(r)
+	}
+	return false
+}
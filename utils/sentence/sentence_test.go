@@ -0,0 +1,38 @@
+package sentence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Split with abbreviations, initials and decimals
+func TestSplitAbbreviations(t *testing.T) {
+	text := "Dr. Smith met J. R. Tolkien in the U.S. last year. The ticket cost 3.50 dollars, e.g. a bargain. Really?"
+	expected := []string{
+		"Dr. Smith met J. R. Tolkien in the U.S. last year.",
+		"The ticket cost 3.50 dollars, e.g. a bargain.",
+		"Really?",
+	}
+	assert.Equal(t, expected, Split(text), "Sentences split incorrectly")
+}
+
+// Test Split with quotes, brackets and ellipses
+func TestSplitQuotes(t *testing.T) {
+	text := `He said "Stop!" She stopped. (It was late.) Then... nothing happened. Wow!?`
+	expected := []string{
+		`He said "Stop!"`,
+		"She stopped.",
+		"(It was late.)",
+		"Then... nothing happened.",
+		"Wow!?",
+	}
+	assert.Equal(t, expected, Split(text), "Sentences split incorrectly")
+}
+
+// Test Split never crosses a paragraph boundary
+func TestSplitParagraphs(t *testing.T) {
+	text := "A heading without period" + ParagraphBreak + "First sentence. Second one" + ParagraphBreak + "  "
+	assert.Equal(t, []string{"A heading without period", "First sentence. Second one"}, Paragraphs(text), "Paragraphs split incorrectly")
+	assert.Equal(t, []string{"A heading without period", "First sentence.", "Second one"}, Split(text), "Sentences split incorrectly")
+}
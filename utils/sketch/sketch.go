@@ -0,0 +1,243 @@
+package sketch
+
+import (
+	"errors"
+	"hash/fnv"
+	"math"
+
+	"github.com/joshy-joy/essay-word-counter/utils/topk"
+)
+
+// Names of the approximate counting algorithms
+const (
+	SpaceSavingAlgorithm    = "spaceSaving"
+	CountMinSketchAlgorithm = "countMinSketch"
+)
+
+// ErrMismatchedSketches is returned when merging sketches built with different bounds
+var ErrMismatchedSketches = errors.New("sketches have different dimensions")
+
+// Capacity returns the number of words to monitor so that the counts are off
+// by at most epsilon times the number of words counted
+func Capacity(epsilon float64) int {
+	return int(math.Ceil(1 / epsilon))
+}
+
+// CountMinSketch estimates word frequencies in a fixed amount of memory. An
+// estimate is never below the true count, and exceeds it by more than epsilon
+// times the number of words counted with a probability of at most delta.
+type CountMinSketch struct {
+	width int
+	depth int
+	table [][]int
+	total int
+}
+
+// NewCountMinSketch creates a sketch of e/epsilon columns and ln(1/delta) rows
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	width := int(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	table := make([][]int, depth)
+	for i := range table {
+		table[i] = make([]int, width)
+	}
+	return &CountMinSketch{width: width, depth: depth, table: table}
+}
+
+// Add counts the word count times and returns its new estimate
+func (s *CountMinSketch) Add(word string, count int) int {
+	s.total += count
+	estimate := math.MaxInt
+	h1, h2 := hash(word)
+	for i, row := range s.table {
+		col := s.column(h1, h2, i)
+		row[col] += count
+		estimate = min(estimate, row[col])
+	}
+	return estimate
+}
+
+// Estimate returns the estimated count of the word
+func (s *CountMinSketch) Estimate(word string) int {
+	estimate := math.MaxInt
+	h1, h2 := hash(word)
+	for i, row := range s.table {
+		estimate = min(estimate, row[s.column(h1, h2, i)])
+	}
+	return estimate
+}
+
+// Merge adds the counts of other, which must have been created with the same bounds
+func (s *CountMinSketch) Merge(other *CountMinSketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return ErrMismatchedSketches
+	}
+	for i, row := range other.table {
+		for j, count := range row {
+			s.table[i][j] += count
+		}
+	}
+	s.total += other.total
+	return nil
+}
+
+// Total returns the number of words counted
+func (s *CountMinSketch) Total() int { return s.total }
+
+// Counters returns the number of counters held by the sketch
+func (s *CountMinSketch) Counters() int { return s.width * s.depth }
+
+// column picks the column of row i with double hashing
+func (s *CountMinSketch) column(h1, h2 uint32, i int) int {
+	return int((uint64(h1) + uint64(i)*uint64(h2)) % uint64(s.width))
+}
+
+func hash(word string) (uint32, uint32) {
+	h := fnv.New64a()
+	h.Write([]byte(word))
+	sum := h.Sum64()
+	// an odd second hash never maps every row to the same column
+	return uint32(sum), uint32(sum>>32) | 1
+}
+
+// HeavyHitters keeps the most frequent words of a Count-Min Sketch in a top-k
+// tracker of the given capacity, the counts being the estimates of the sketch
+type HeavyHitters struct {
+	sketch   *CountMinSketch
+	tracker  *topk.Tracker[string, int]
+	capacity int
+}
+
+// NewHeavyHitters creates a Count-Min Sketch with the given bounds tracking
+// the capacity most frequent words
+func NewHeavyHitters(epsilon, delta float64, capacity int) *HeavyHitters {
+	return &HeavyHitters{sketch: NewCountMinSketch(epsilon, delta), tracker: topk.NewOrdered[string, int](capacity), capacity: capacity}
+}
+
+// Add counts the word count times
+func (h *HeavyHitters) Add(word string, count int) {
+	h.track(word, h.sketch.Add(word, count))
+}
+
+// track keeps the word if its estimate is among the largest ones
+func (h *HeavyHitters) track(word string, estimate int) {
+	if _, ok := h.tracker.Get(word); ok || !h.tracker.Full() {
+		h.tracker.Set(word, estimate)
+		return
+	}
+	if min, _ := h.tracker.Min(); estimate > min.Score {
+		h.tracker.Set(word, estimate)
+	}
+}
+
+// Merge adds the counts of other, the tracked words of both being estimated again
+// with the merged sketch
+func (h *HeavyHitters) Merge(other *HeavyHitters) error {
+	if err := h.sketch.Merge(other.sketch); err != nil {
+		return err
+	}
+	words := h.tracker.Top(0)
+	h.tracker = topk.NewOrdered[string, int](h.capacity)
+	for _, item := range append(words, other.tracker.Top(0)...) {
+		h.track(item.Key, h.sketch.Estimate(item.Key))
+	}
+	return nil
+}
+
+// Estimate returns the estimated count of the word
+func (h *HeavyHitters) Estimate(word string) int { return h.sketch.Estimate(word) }
+
+// Top returns the n most frequent words in descending order of count
+func (h *HeavyHitters) Top(n int) []topk.Item[string, int] { return h.tracker.Top(n) }
+
+// Total returns the number of words counted
+func (h *HeavyHitters) Total() int { return h.sketch.Total() }
+
+// Counters returns the number of counters held by the sketch and the tracker
+func (h *HeavyHitters) Counters() int { return h.sketch.Counters() + h.capacity }
+
+// SpaceSaving monitors at most capacity words. When a new word arrives and every
+// counter is taken, the word with the smallest count is replaced and the new word
+// inherits its count, so a count exceeds the true one by at most the number of
+// words counted divided by the capacity.
+type SpaceSaving struct {
+	tracker  *topk.Tracker[string, int]
+	errors   map[string]int // overestimation of each monitored word
+	capacity int
+	total    int
+}
+
+// NewSpaceSaving creates a summary monitoring at most capacity words
+func NewSpaceSaving(capacity int) *SpaceSaving {
+	return &SpaceSaving{tracker: topk.NewOrdered[string, int](capacity), errors: make(map[string]int), capacity: capacity}
+}
+
+// Add counts the word count times
+func (s *SpaceSaving) Add(word string, count int) {
+	s.total += count
+	if _, ok := s.tracker.Get(word); ok || !s.tracker.Full() {
+		s.tracker.Add(word, count)
+		return
+	}
+	evicted, _ := s.tracker.Pop()
+	delete(s.errors, evicted.Key)
+	s.tracker.Set(word, evicted.Score+count)
+	s.errors[word] = evicted.Score
+}
+
+// Merge adds the counts of other. A word monitored by only one of the summaries is
+// counted in the other one as the smallest count of a full summary, so the merged
+// counts keep the same error bound.
+func (s *SpaceSaving) Merge(other *SpaceSaving) {
+	counts := make(map[string]int)
+	errs := make(map[string]int)
+	for _, summary := range []*SpaceSaving{s, other} {
+		for _, item := range summary.tracker.Top(0) {
+			counts[item.Key] += item.Score
+			errs[item.Key] += summary.errors[item.Key]
+		}
+	}
+	for _, summary := range []*SpaceSaving{s, other} {
+		if !summary.tracker.Full() {
+			continue
+		}
+		floor, _ := summary.tracker.Min()
+		for word := range counts {
+			if _, ok := summary.tracker.Get(word); !ok {
+				counts[word] += floor.Score
+				errs[word] += floor.Score
+			}
+		}
+	}
+
+	// the tracker keeps the capacity largest merged counts
+	s.tracker = topk.NewOrdered[string, int](s.capacity)
+	for word, count := range counts {
+		s.tracker.Set(word, count)
+	}
+	s.errors = make(map[string]int)
+	for _, item := range s.tracker.Top(0) {
+		if errs[item.Key] > 0 {
+			s.errors[item.Key] = errs[item.Key]
+		}
+	}
+	s.total += other.total
+}
+
+// Estimate returns the count of a monitored word, or 0 for other words
+func (s *SpaceSaving) Estimate(word string) int {
+	count, _ := s.tracker.Get(word)
+	return count
+}
+
+// Error returns by how much the count of a monitored word may exceed its true count
+func (s *SpaceSaving) Error(word string) int { return s.errors[word] }
+
+// Top returns the n most frequent words in descending order of count
+func (s *SpaceSaving) Top(n int) []topk.Item[string, int] { return s.tracker.Top(n) }
+
+// Total returns the number of words counted
+func (s *SpaceSaving) Total() int { return s.total }
+
+// Counters returns the number of counters held by the summary
+func (s *SpaceSaving) Counters() int { return s.capacity }
@@ -0,0 +1,111 @@
+package sketch
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// zipf returns the word frequencies of a skewed corpus, word i appearing 1000/(i+1) times
+func zipf(words int) map[string]int {
+	freq := make(map[string]int, words)
+	for i := 0; i < words; i++ {
+		freq[fmt.Sprintf("word%d", i)] = 1000/(i+1) + 1
+	}
+	return freq
+}
+
+// Test the Count-Min Sketch never underestimates and stays within its error bound
+func TestCountMinSketchBounds(t *testing.T) {
+	freq := zipf(2000)
+	s := NewCountMinSketch(0.01, 0.01)
+	for word, count := range freq {
+		s.Add(word, count)
+	}
+	assert.Equal(t, 272, s.width, "Expected e/epsilon columns")
+	assert.Equal(t, 5, s.depth, "Expected ln(1/delta) rows")
+
+	bound := int(0.01 * float64(s.Total()))
+	for word, count := range freq {
+		estimate := s.Estimate(word)
+		assert.GreaterOrEqual(t, estimate, count, "Expected no underestimate for %q", word)
+		assert.LessOrEqual(t, estimate-count, bound, "Expected the error of %q within the bound", word)
+	}
+}
+
+// Test merging sketches adds their counts
+func TestCountMinSketchMerge(t *testing.T) {
+	a, b := NewCountMinSketch(0.01, 0.01), NewCountMinSketch(0.01, 0.01)
+	a.Add("essay", 3)
+	b.Add("essay", 4)
+	assert.Nil(t, a.Merge(b), "Expected no error merging sketches of the same size")
+	assert.Equal(t, 7, a.Estimate("essay"), "Expected the merged count")
+	assert.Equal(t, 7, a.Total(), "Expected the merged total")
+	assert.Equal(t, ErrMismatchedSketches, a.Merge(NewCountMinSketch(0.1, 0.01)), "Expected an error for different dimensions")
+}
+
+// Test the heavy hitters of a sketch are the most frequent words
+func TestHeavyHittersTop(t *testing.T) {
+	a, b := NewHeavyHitters(0.001, 0.01, 10), NewHeavyHitters(0.001, 0.01, 10)
+	i := 0
+	for word, count := range zipf(500) {
+		// split the corpus between two workers
+		if i%2 == 0 {
+			a.Add(word, count)
+		} else {
+			b.Add(word, count)
+		}
+		i++
+	}
+	assert.Nil(t, a.Merge(b), "Expected no error merging heavy hitters")
+
+	top := a.Top(3)
+	assert.Equal(t, []string{"word0", "word1", "word2"}, []string{top[0].Key, top[1].Key, top[2].Key}, "Expected the most frequent words in descending order")
+	assert.Equal(t, 1001, top[0].Score, "Expected the count of the most frequent word")
+}
+
+// Test Space-Saving keeps the most frequent words within its error bound
+func TestSpaceSavingBounds(t *testing.T) {
+	freq := zipf(2000)
+	s := NewSpaceSaving(Capacity(0.01))
+	for i := 0; i < 2000; i++ {
+		word := fmt.Sprintf("word%d", i)
+		s.Add(word, freq[word])
+	}
+	assert.Equal(t, 100, s.Counters(), "Expected 1/epsilon counters")
+
+	bound := s.Total() / s.Counters()
+	for _, item := range s.Top(10) {
+		assert.GreaterOrEqual(t, item.Score, freq[item.Key], "Expected no underestimate for %q", item.Key)
+		assert.LessOrEqual(t, s.Error(item.Key), bound, "Expected the error of %q within the bound", item.Key)
+	}
+	assert.Equal(t, "word0", s.Top(1)[0].Key, "Expected the most frequent word")
+}
+
+// Test Space-Saving replaces the smallest counter when full
+func TestSpaceSavingEviction(t *testing.T) {
+	s := NewSpaceSaving(2)
+	s.Add("alpha", 5)
+	s.Add("beta", 2)
+	s.Add("gamma", 1)
+	assert.Equal(t, 0, s.Estimate("beta"), "Expected 'beta' to be evicted")
+	assert.Equal(t, 3, s.Estimate("gamma"), "Expected 'gamma' to inherit the evicted count")
+	assert.Equal(t, 2, s.Error("gamma"), "Expected the inherited count as error")
+}
+
+// Test merging Space-Saving summaries
+func TestSpaceSavingMerge(t *testing.T) {
+	a, b := NewSpaceSaving(2), NewSpaceSaving(2)
+	a.Add("alpha", 5)
+	a.Add("beta", 2)
+	b.Add("alpha", 3)
+	b.Add("gamma", 4)
+	a.Merge(b)
+
+	assert.Equal(t, 14, a.Total(), "Expected the merged total")
+	assert.Equal(t, 8, a.Estimate("alpha"), "Expected the counts of both summaries")
+	assert.Equal(t, 6, a.Estimate("gamma"), "Expected the smallest count of the first summary to be added")
+	assert.Equal(t, 2, a.Error("gamma"), "Expected the added count as error")
+	assert.Equal(t, 0, a.Estimate("beta"), "Expected 'beta' to be dropped")
+}
@@ -0,0 +1,116 @@
+package textstats
+
+import (
+	"math"
+	"strings"
+)
+
+// Stats holds the word counts and readability scores of a single document.
+type Stats struct {
+	Words              int     `json:"words"`
+	UniqueWords        int     `json:"uniqueWords"`
+	Sentences          int     `json:"sentences"`
+	Paragraphs         int     `json:"paragraphs"`
+	AvgSentenceLength  float64 `json:"avgSentenceLength"`
+	AvgParagraphLength float64 `json:"avgParagraphLength"`
+	TypeTokenRatio     float64 `json:"typeTokenRatio"`
+	FleschKincaidGrade float64 `json:"fleschKincaidGrade"`
+	GunningFog         float64 `json:"gunningFog"`
+}
+
+// Counter accumulates the statistics of a document one paragraph at a time
+type Counter struct {
+	stats        Stats
+	unique       map[string]struct{}
+	syllables    int
+	complexWords int
+}
+
+// NewCounter creates a counter for a new document
+func NewCounter() *Counter {
+	return &Counter{unique: make(map[string]struct{})}
+}
+
+// AddParagraph adds a paragraph given as the list of its sentences, each
+// sentence being the list of its (lower-cased) words.
+func (c *Counter) AddParagraph(sentences [][]string) {
+	count := 0
+	for _, sentence := range sentences {
+		if len(sentence) == 0 {
+			continue
+		}
+		count++
+		for _, word := range sentence {
+			c.stats.Words++
+			c.unique[word] = struct{}{}
+			n := CountSyllables(word)
+			c.syllables += n
+			// Gunning Fog treats words with three or more syllables as complex
+			if n >= 3 {
+				c.complexWords++
+			}
+		}
+	}
+	if count > 0 {
+		c.stats.Paragraphs++
+		c.stats.Sentences += count
+	}
+}
+
+// Stats computes the statistics of the paragraphs added so far
+func (c *Counter) Stats() Stats {
+	stats := c.stats
+	stats.UniqueWords = len(c.unique)
+
+	if stats.Words == 0 {
+		return stats
+	}
+	wordsPerSentence := float64(stats.Words) / float64(stats.Sentences)
+	syllablesPerWord := float64(c.syllables) / float64(stats.Words)
+	complexRatio := float64(c.complexWords) / float64(stats.Words)
+
+	stats.AvgSentenceLength = round(wordsPerSentence)
+	stats.AvgParagraphLength = round(float64(stats.Sentences) / float64(stats.Paragraphs))
+	stats.TypeTokenRatio = round(float64(stats.UniqueWords) / float64(stats.Words))
+	stats.FleschKincaidGrade = round(0.39*wordsPerSentence + 11.8*syllablesPerWord - 15.59)
+	stats.GunningFog = round(0.4 * (wordsPerSentence + 100*complexRatio))
+	return stats
+}
+
+// Compute calculates the statistics of a document given its paragraphs, each
+// paragraph being the list of its sentences and each sentence the list of its
+// (lower-cased) words.
+func Compute(paragraphs [][][]string) Stats {
+	c := NewCounter()
+	for _, paragraph := range paragraphs {
+		c.AddParagraph(paragraph)
+	}
+	return c.Stats()
+}
+
+// CountSyllables estimates the number of syllables of an english word by
+// counting groups of vowels, with the usual adjustment for a silent trailing 'e'.
+func CountSyllables(word string) int {
+	word = strings.ToLower(word)
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+	if count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+	return count
+}
+
+// round keeps two decimals so that the scores stay readable in the output
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
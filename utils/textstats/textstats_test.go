@@ -0,0 +1,36 @@
+package textstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test CountSyllables with common english words
+func TestCountSyllables(t *testing.T) {
+	assert.Equal(t, 1, CountSyllables("cat"), "Expected 1 syllable for 'cat'")
+	assert.Equal(t, 1, CountSyllables("make"), "Expected silent 'e' to be ignored")
+	assert.Equal(t, 2, CountSyllables("table"), "Expected trailing 'le' to count")
+	assert.Equal(t, 3, CountSyllables("beautiful"), "Expected 3 syllables for 'beautiful'")
+	assert.Equal(t, 1, CountSyllables("rhythm"), "Expected 'y' to be treated as a vowel")
+}
+
+// Test Compute with a short document
+func TestComputeSuccess(t *testing.T) {
+	stats := Compute([][][]string{{{"the", "cat", "sat"}, {"the", "dog", "ran", "away"}}, {{"bye"}}})
+	assert.Equal(t, 8, stats.Words, "Expected 8 words")
+	assert.Equal(t, 7, stats.UniqueWords, "Expected 7 unique words")
+	assert.Equal(t, 3, stats.Sentences, "Expected 3 sentences")
+	assert.Equal(t, 2, stats.Paragraphs, "Expected 2 paragraphs")
+	assert.Equal(t, 2.67, stats.AvgSentenceLength, "Expected 2.67 words per sentence")
+	assert.Equal(t, 1.5, stats.AvgParagraphLength, "Expected 1.5 sentences per paragraph")
+	assert.Equal(t, 0.88, stats.TypeTokenRatio, "Expected type-token ratio of 0.88")
+	assert.Equal(t, -1.27, stats.FleschKincaidGrade, "Flesch-Kincaid grade mismatch")
+	assert.Equal(t, 1.07, stats.GunningFog, "Gunning Fog index mismatch")
+}
+
+// Test Compute with an empty document
+func TestComputeEmpty(t *testing.T) {
+	stats := Compute(nil)
+	assert.Equal(t, Stats{}, stats, "Expected zero stats for an empty document")
+}
@@ -0,0 +1,146 @@
+package topk
+
+import (
+	"cmp"
+	"container/heap"
+	"sort"
+)
+
+// Score is the type of the values keys are ranked by
+type Score interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Item is a key and its score
+type Item[K comparable, S Score] struct {
+	Key   K
+	Score S
+}
+
+// Tracker keeps the keys with the highest scores. Keys are ranked by score, ties
+// being broken by the compare function so that the order is always the same.
+// Once the capacity is reached, adding a key evicts the lowest ranked one.
+type Tracker[K comparable, S Score] struct {
+	h        *items[K, S]
+	capacity int
+}
+
+// New creates a tracker keeping at most capacity keys, or every key when capacity
+// is 0. Keys with equal scores are ranked in the ascending order of compare.
+func New[K comparable, S Score](capacity int, compare func(a, b K) int) *Tracker[K, S] {
+	return &Tracker[K, S]{
+		h:        &items[K, S]{index: make(map[K]int), compare: compare},
+		capacity: capacity,
+	}
+}
+
+// NewOrdered creates a tracker whose keys with equal scores are ranked in lexical order
+func NewOrdered[K cmp.Ordered, S Score](capacity int) *Tracker[K, S] {
+	return New[K, S](capacity, cmp.Compare[K])
+}
+
+// Add increments the score of the key, inserting it when it is not tracked,
+// and returns its new score
+func (t *Tracker[K, S]) Add(key K, delta S) S {
+	if i, ok := t.h.index[key]; ok {
+		t.h.items[i].Score += delta
+		heap.Fix(t.h, i)
+		return t.h.items[i].Score
+	}
+	t.Set(key, delta)
+	return delta
+}
+
+// Set replaces the score of the key, inserting it when it is not tracked
+func (t *Tracker[K, S]) Set(key K, score S) {
+	if i, ok := t.h.index[key]; ok {
+		t.h.items[i].Score = score
+		heap.Fix(t.h, i)
+		return
+	}
+	heap.Push(t.h, Item[K, S]{Key: key, Score: score})
+	if t.capacity > 0 && t.h.Len() > t.capacity {
+		heap.Pop(t.h)
+	}
+}
+
+// Get returns the score of the key if it is tracked
+func (t *Tracker[K, S]) Get(key K) (S, bool) {
+	i, ok := t.h.index[key]
+	if !ok {
+		var zero S
+		return zero, false
+	}
+	return t.h.items[i].Score, true
+}
+
+// Min returns the lowest ranked key without removing it
+func (t *Tracker[K, S]) Min() (Item[K, S], bool) {
+	if t.h.Len() == 0 {
+		return Item[K, S]{}, false
+	}
+	return t.h.items[0], true
+}
+
+// Pop removes and returns the lowest ranked key
+func (t *Tracker[K, S]) Pop() (Item[K, S], bool) {
+	if t.h.Len() == 0 {
+		return Item[K, S]{}, false
+	}
+	return heap.Pop(t.h).(Item[K, S]), true
+}
+
+// Len returns the number of tracked keys
+func (t *Tracker[K, S]) Len() int { return t.h.Len() }
+
+// Full reports whether adding a new key would evict another one
+func (t *Tracker[K, S]) Full() bool { return t.capacity > 0 && t.h.Len() >= t.capacity }
+
+// Top returns the n highest ranked keys in descending order, or every key when n is 0
+func (t *Tracker[K, S]) Top(n int) []Item[K, S] {
+	result := append([]Item[K, S](nil), t.h.items...)
+	sort.Slice(result, func(i, j int) bool { return t.h.ranksBefore(result[i], result[j]) })
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// items implements heap.Interface with the lowest ranked key at the top
+type items[K comparable, S Score] struct {
+	items   []Item[K, S]
+	index   map[K]int // position of every key in items
+	compare func(a, b K) int
+}
+
+// ranksBefore reports whether a ranks higher than b
+func (h *items[K, S]) ranksBefore(a, b Item[K, S]) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return h.compare(a.Key, b.Key) < 0
+}
+
+func (h *items[K, S]) Len() int           { return len(h.items) }
+func (h *items[K, S]) Less(i, j int) bool { return h.ranksBefore(h.items[j], h.items[i]) }
+func (h *items[K, S]) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Key] = i
+	h.index[h.items[j].Key] = j
+}
+
+func (h *items[K, S]) Push(x any) {
+	item := x.(Item[K, S])
+	h.index[item.Key] = len(h.items)
+	h.items = append(h.items, item)
+}
+
+func (h *items[K, S]) Pop() any {
+	n := len(h.items)
+	item := h.items[n-1]
+	h.items = h.items[:n-1]
+	delete(h.index, item.Key)
+	return item
+}
@@ -0,0 +1,76 @@
+package topk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Test Add increments tracked keys and inserts new ones
+func TestTrackerAdd(t *testing.T) {
+	tracker := NewOrdered[string, int](0)
+	tracker.Add("essay", 2)
+	assert.Equal(t, 5, tracker.Add("essay", 3), "Expected the score to be incremented")
+	tracker.Add("word", 1)
+
+	score, ok := tracker.Get("essay")
+	assert.True(t, ok, "Expected 'essay' to be tracked")
+	assert.Equal(t, 5, score, "Expected the incremented score")
+	assert.Equal(t, 2, tracker.Len(), "Expected 2 tracked keys")
+}
+
+// Test Top reports in descending order with ties broken lexically
+func TestTrackerTopOrder(t *testing.T) {
+	tracker := NewOrdered[string, int](0)
+	for _, word := range []string{"delta", "beta", "alpha", "gamma"} {
+		tracker.Set(word, 2)
+	}
+	tracker.Set("omega", 3)
+
+	expected := []Item[string, int]{{"omega", 3}, {"alpha", 2}, {"beta", 2}, {"delta", 2}, {"gamma", 2}}
+	assert.Equal(t, expected, tracker.Top(0), "Expected descending scores, ties in lexical order")
+	assert.Equal(t, expected[:2], tracker.Top(2), "Expected the 2 highest ranked keys")
+}
+
+// Test the lowest ranked key is evicted once the capacity is reached
+func TestTrackerCapacity(t *testing.T) {
+	tracker := NewOrdered[string, int](2)
+	tracker.Set("alpha", 2)
+	tracker.Set("beta", 2)
+	assert.True(t, tracker.Full(), "Expected the tracker to be full")
+	tracker.Set("gamma", 5)
+
+	_, ok := tracker.Get("beta")
+	assert.False(t, ok, "Expected 'beta' to be evicted as the lexically last of the lowest scores")
+	min, _ := tracker.Min()
+	assert.Equal(t, Item[string, int]{"alpha", 2}, min, "Expected 'alpha' to be the lowest ranked key")
+	popped, _ := tracker.Pop()
+	assert.Equal(t, "alpha", popped.Key, "Expected Pop to remove the lowest ranked key")
+	assert.Equal(t, 1, tracker.Len(), "Expected 1 tracked key")
+}
+
+// Test a tracker with custom keys and a floating point score
+func TestTrackerCustomKeys(t *testing.T) {
+	type bigram [2]string
+	tracker := New[bigram, float64](0, func(a, b bigram) int {
+		return strings.Compare(a[0]+" "+a[1], b[0]+" "+b[1])
+	})
+	tracker.Add(bigram{"new", "york"}, 0.5)
+	tracker.Add(bigram{"los", "angeles"}, 0.5)
+	tracker.Add(bigram{"new", "york"}, 0.25)
+
+	top := tracker.Top(0)
+	assert.Equal(t, bigram{"new", "york"}, top[0].Key, "Expected the highest scored bigram first")
+	assert.Equal(t, 0.75, top[0].Score, "Expected the summed score")
+}
+
+// Test Min and Pop on an empty tracker
+func TestTrackerEmpty(t *testing.T) {
+	tracker := NewOrdered[string, int](1)
+	_, ok := tracker.Min()
+	assert.False(t, ok, "Expected no minimum in an empty tracker")
+	_, ok = tracker.Pop()
+	assert.False(t, ok, "Expected nothing to pop from an empty tracker")
+	assert.Empty(t, tracker.Top(3), "Expected no keys")
+}
@@ -3,18 +3,8 @@ package utils
 import (
 	"bytes"
 	"encoding/json"
-	"os"
-	"strings"
 )
 
-func ReadFile(path string) ([]string, error) {
-	content, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-	return strings.Split(string(content), "\n"), nil
-}
-
 func PrettyPrintJSON(data interface{}) (*bytes.Buffer, error) {
 	b, err := json.Marshal(data)
 	if err != nil {